@@ -12,7 +12,11 @@ func NewBinding(l uint32) *Binding {
 	return &Binding{l}
 }
 
-// Set Binds this Binding to the provided buffer.
+// Set Binds this Binding to the provided buffer. It does nothing on a nil Binding, which is a buffer the program doesn't
+// use.
 func (b *Binding) Set(buf uint32) {
+	if b == nil {
+		return
+	}
 	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, b.uint32, buf)
 }
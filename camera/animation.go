@@ -0,0 +1,209 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/brandonnelson3/GameEngine/window"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var animations []*Animation
+
+// Viewpoint is everything needed to place a camera, which is where it is and which way it is facing.
+type Viewpoint struct {
+	Position    mgl32.Vec3
+	Orientation mgl32.Quat
+}
+
+// Posable is a camera which can be placed at any Viewpoint, and so can be animated.
+type Posable interface {
+	Camera
+	// GetViewpoint returns where this camera currently is and which way it is facing.
+	GetViewpoint() Viewpoint
+	// SetViewpoint moves and turns this camera to the provided Viewpoint.
+	SetViewpoint(v Viewpoint)
+}
+
+// OrientationFromAngles returns the orientation of a camera which has been turned horizontally, in radians, around the y
+// axis starting from facing along the x axis, and then vertically, in radians, up from the horizon.
+func OrientationFromAngles(horizontal, vertical float32) mgl32.Quat {
+	// Cameras face along -z before being turned, which is a quarter turn from the x axis.
+	return mgl32.QuatRotate(horizontal-math.Pi/2, localUp).Mul(mgl32.QuatRotate(vertical, localRight)).Normalize()
+}
+
+// Interpolate returns the Viewpoint amount of the way from a to b, where 0 is a and 1 is b. Positions are interpolated
+// linearly, and orientations are spherically interpolated along the shortest arc.
+func Interpolate(a, b Viewpoint, amount float32) Viewpoint {
+	to := b.Orientation
+	// q and -q are the same orientation, but slerping towards the wrong one goes the long way around.
+	if a.Orientation.Dot(to) < 0 {
+		to = to.Scale(-1)
+	}
+	return Viewpoint{
+		Position:    a.Position.Add(b.Position.Sub(a.Position).Mul(amount)),
+		Orientation: mgl32.QuatSlerp(a.Orientation, to, amount).Normalize(),
+	}
+}
+
+// Track is anything an Animation can move a camera along.
+type Track interface {
+	// Sample returns the Viewpoint t seconds after the start of the track.
+	Sample(t float64) Viewpoint
+	// Duration returns the length of the track in seconds.
+	Duration() float64
+}
+
+// FovTrack is a Track which also animates the field of view.
+type FovTrack interface {
+	Track
+	// SampleFov returns the field of view, in degrees, t seconds after the start of the track, and whether the track
+	// sets the field of view at all.
+	SampleFov(t float64) (float32, bool)
+}
+
+// Keyframe is a Viewpoint which an Animation passes through, at Time seconds after it starts.
+type Keyframe struct {
+	Time      float64
+	Viewpoint Viewpoint
+}
+
+// Keyframes is a Track which moves in a straight line between each pair of Keyframes, which must be in order of time.
+type Keyframes []Keyframe
+
+// Duration returns the time of the last Keyframe.
+func (k Keyframes) Duration() float64 {
+	if len(k) == 0 {
+		return 0
+	}
+	return k[len(k)-1].Time
+}
+
+// Sample returns the Viewpoint t seconds after the first Keyframe.
+func (k Keyframes) Sample(t float64) Viewpoint {
+	if len(k) == 0 {
+		return Viewpoint{Orientation: mgl32.QuatIdent()}
+	}
+	if t <= k[0].Time {
+		return k[0].Viewpoint
+	}
+	for i := 1; i < len(k); i++ {
+		k0, k1 := k[i-1], k[i]
+		if t < k1.Time {
+			return Interpolate(k0.Viewpoint, k1.Viewpoint, float32((t-k0.Time)/(k1.Time-k0.Time)))
+		}
+	}
+	return k[len(k)-1].Viewpoint
+}
+
+// Animation moves a Posable camera along a Track. Animations are advanced by Update, so they play back in step with
+// everything else driven by the frame length, including when input is being replayed.
+type Animation struct {
+	camera  Posable
+	track   Track
+	elapsed float64
+	done    bool
+
+	// Ease remaps the fraction of the whole animation which has elapsed, such as to speed up and slow down gently at
+	// either end. It is linear if nil.
+	Ease func(float64) float64
+	// FixedStep, if it is greater than 0, is how many seconds the animation advances every frame regardless of how long
+	// the frame took, so that every run renders exactly the same sequence of frames.
+	FixedStep float64
+}
+
+// Animate starts moving c through the provided keyframes, which must be in order of time. Any animation already playing
+// on c is stopped.
+func Animate(c Posable, keyframes []Keyframe) *Animation {
+	return Play(c, Keyframes(keyframes))
+}
+
+// Play starts moving c along the provided Track. Any animation already playing on c is stopped.
+func Play(c Posable, t Track) *Animation {
+	for _, a := range animations {
+		if a.camera == c {
+			a.Stop()
+		}
+	}
+	a := &Animation{camera: c, track: t}
+	animations = append(animations, a)
+	return a
+}
+
+// TransitionTo starts smoothly moving c from wherever it is to the provided Viewpoint over duration seconds.
+func TransitionTo(c Posable, v Viewpoint, duration float64) *Animation {
+	a := Animate(c, []Keyframe{{0, c.GetViewpoint()}, {duration, v}})
+	a.Ease = SmoothStep
+	return a
+}
+
+// SmoothStep is an easing function which starts and ends slowly.
+func SmoothStep(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+// Stop stops this animation, leaving the camera wherever it currently is.
+func (a *Animation) Stop() {
+	a.done = true
+}
+
+// Done returns whether this animation has finished or been stopped.
+func (a *Animation) Done() bool {
+	return a.done
+}
+
+// Duration returns the length of this animation in seconds.
+func (a *Animation) Duration() float64 {
+	return a.track.Duration()
+}
+
+// Elapsed returns how far into this animation, in seconds, the camera has been moved.
+func (a *Animation) Elapsed() float64 {
+	return a.elapsed
+}
+
+// trackTime returns the time on the track this animation is at t seconds after it started.
+func (a *Animation) trackTime(t float64) float64 {
+	duration := a.Duration()
+	if a.Ease != nil && duration > 0 {
+		return a.Ease(math.Max(0, math.Min(t/duration, 1))) * duration
+	}
+	return t
+}
+
+// Sample returns the Viewpoint this animation is at t seconds after it started.
+func (a *Animation) Sample(t float64) Viewpoint {
+	return a.track.Sample(a.trackTime(t))
+}
+
+// update advances this animation by d seconds and moves its camera.
+func (a *Animation) update(d float64) {
+	if a.done {
+		return
+	}
+	if a.FixedStep > 0 {
+		d = a.FixedStep
+	}
+	a.elapsed = math.Min(a.elapsed+d, a.Duration())
+	a.camera.SetViewpoint(a.Sample(a.elapsed))
+	if f, ok := a.track.(FovTrack); ok {
+		if fov, ok := f.SampleFov(a.trackTime(a.elapsed)); ok {
+			window.SetFov(fov)
+		}
+	}
+	if a.elapsed >= a.Duration() {
+		a.done = true
+	}
+}
+
+// updateAnimations advances every playing animation, and forgets those which have finished.
+func updateAnimations(d float64) {
+	playing := animations[:0]
+	for _, a := range animations {
+		a.update(d)
+		if !a.done {
+			playing = append(playing, a)
+		}
+	}
+	animations = playing
+}
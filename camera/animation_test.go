@@ -0,0 +1,92 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// facing returns the direction a camera with orientation q looks in, which is the same for q and -q.
+func facing(q mgl32.Quat) mgl32.Vec3 {
+	return q.Rotate(localForward)
+}
+
+// near returns whether a and b are within a rounding error of each other.
+func near(a, b mgl32.Vec3) bool {
+	return a.Sub(b).Len() < 1e-5
+}
+
+func TestOrientationFromAnglesMatchesYawAndPitch(t *testing.T) {
+	for _, c := range []struct{ horizontal, vertical float32 }{
+		{0, 0},
+		{math.Pi / 2, 0},
+		{math.Pi, 0.3},
+		{5.506999, -0.476000},
+		{-1, 1.5},
+	} {
+		// This is how the forward vector was found from the angles before cameras held a quaternion.
+		want := mgl32.Rotate3DY(c.horizontal).Mul3x1(mgl32.Rotate3DZ(c.vertical).Mul3x1(mgl32.Vec3{1, 0, 0}))
+		if got := facing(OrientationFromAngles(c.horizontal, c.vertical)); !near(got, want) {
+			t.Errorf("OrientationFromAngles(%v, %v) faces %v, want %v", c.horizontal, c.vertical, got, want)
+		}
+	}
+}
+
+func TestInterpolateTakesTheShortestArc(t *testing.T) {
+	a := Viewpoint{Position: mgl32.Vec3{0, 0, 0}, Orientation: mgl32.QuatIdent()}
+	b := Viewpoint{Position: mgl32.Vec3{2, 4, -6}, Orientation: mgl32.QuatRotate(math.Pi/2, up)}
+	want := Viewpoint{Position: mgl32.Vec3{1, 2, -3}, Orientation: mgl32.QuatRotate(math.Pi/4, up)}
+
+	for _, c := range []struct {
+		name string
+		to   mgl32.Quat
+	}{
+		{"q", b.Orientation},
+		// -q is the same orientation as q, but has a negative dot product with a, so a slerp which doesn't account for
+		// that turns the long way around.
+		{"-q", b.Orientation.Scale(-1)},
+	} {
+		got := Interpolate(a, Viewpoint{b.Position, c.to}, 0.5)
+		if !near(got.Position, want.Position) {
+			t.Errorf("halfway to %s is at %v, want %v", c.name, got.Position, want.Position)
+		}
+		if !near(facing(got.Orientation), facing(want.Orientation)) {
+			t.Errorf("halfway to %s faces %v, want %v", c.name, facing(got.Orientation), facing(want.Orientation))
+		}
+	}
+}
+
+func TestKeyframesSample(t *testing.T) {
+	k := Keyframes{
+		{Time: 1, Viewpoint: Viewpoint{mgl32.Vec3{0, 0, 0}, mgl32.QuatIdent()}},
+		{Time: 3, Viewpoint: Viewpoint{mgl32.Vec3{4, 0, 0}, mgl32.QuatRotate(math.Pi/2, up)}},
+		{Time: 4, Viewpoint: Viewpoint{mgl32.Vec3{4, 2, 0}, mgl32.QuatRotate(math.Pi/2, up)}},
+	}
+	if got := k.Duration(); got != 4 {
+		t.Errorf("got a duration of %v, want 4", got)
+	}
+
+	for _, c := range []struct {
+		t        float64
+		position mgl32.Vec3
+		turned   float32
+	}{
+		{0, mgl32.Vec3{0, 0, 0}, 0},
+		{1, mgl32.Vec3{0, 0, 0}, 0},
+		{2, mgl32.Vec3{2, 0, 0}, math.Pi / 4},
+		{2.5, mgl32.Vec3{3, 0, 0}, 3 * math.Pi / 8},
+		{3, mgl32.Vec3{4, 0, 0}, math.Pi / 2},
+		{3.5, mgl32.Vec3{4, 1, 0}, math.Pi / 2},
+		{5, mgl32.Vec3{4, 2, 0}, math.Pi / 2},
+	} {
+		got := k.Sample(c.t)
+		if !near(got.Position, c.position) {
+			t.Errorf("at %v got position %v, want %v", c.t, got.Position, c.position)
+		}
+		want := facing(mgl32.QuatRotate(c.turned, up))
+		if !near(facing(got.Orientation), want) {
+			t.Errorf("at %v facing %v, want %v", c.t, facing(got.Orientation), want)
+		}
+	}
+}
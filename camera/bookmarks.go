@@ -0,0 +1,211 @@
+package camera
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/brandonnelson3/GameEngine/input"
+	"github.com/brandonnelson3/GameEngine/messagebus"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// bookmarkTransitionTime is how long, in seconds, the camera takes to fly to a bookmark.
+	bookmarkTransitionTime = 1.0
+)
+
+var (
+	// JumpTopic receives a Jump whenever a camera should fly to a bookmark.
+	JumpTopic = messagebus.NewTopic[Jump]("camerajump")
+
+	bookmarks     []Bookmark
+	bookmarksFile string
+	// currentBookmark is the bookmark which was most recently jumped to or saved, or -1.
+	currentBookmark = -1
+)
+
+// Jump is message data which flies the active camera to the named bookmark.
+type Jump struct {
+	Bookmark string
+	// Duration is how long the flight takes, in seconds. The camera is moved there immediately if it is 0.
+	Duration float64
+}
+
+// Bookmark is a named Viewpoint, as stored in a bookmarks file.
+type Bookmark struct {
+	Name     string     `json:"name"`
+	Position mgl32.Vec3 `json:"position"`
+	// Orientation is the quaternion's W, X, Y and Z.
+	Orientation [4]float32 `json:"orientation"`
+}
+
+// NewBookmark returns a Bookmark for the provided Viewpoint.
+func NewBookmark(name string, v Viewpoint) Bookmark {
+	q := v.Orientation
+	return Bookmark{Name: name, Position: v.Position, Orientation: [4]float32{q.W, q.X(), q.Y(), q.Z()}}
+}
+
+// Viewpoint returns the Viewpoint this Bookmark was made from.
+func (b Bookmark) Viewpoint() Viewpoint {
+	o := b.Orientation
+	return Viewpoint{b.Position, mgl32.Quat{W: o[0], V: mgl32.Vec3{o[1], o[2], o[3]}}.Normalize()}
+}
+
+// UserBookmarksFile returns the file in the user's config directory which bookmarks are saved to, so that saving one
+// doesn't change the bookmarks which are checked in alongside the engine.
+func UserBookmarksFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user config directory: %v", err)
+	}
+	return filepath.Join(dir, "GameEngine", "bookmarks.json"), nil
+}
+
+// LoadBookmarks replaces every bookmark with those in the provided JSON bookmarks file.
+func LoadBookmarks(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read bookmarks %q: %v", file, err)
+	}
+	var b []Bookmark
+	if err := json.Unmarshal(data, &b); err != nil {
+		return fmt.Errorf("failed to parse bookmarks %q: %v", file, err)
+	}
+	bookmarks = b
+	currentBookmark = -1
+	return nil
+}
+
+// SaveBookmarks writes every bookmark to the provided file, in the format read by LoadBookmarks, creating its directory
+// if needed.
+func SaveBookmarks(file string) error {
+	data, err := json.MarshalIndent(bookmarks, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+	bookmarksFile = file
+	return nil
+}
+
+// SetBookmarksFile sets the file bookmarks are written back to when one is saved, without loading it.
+func SetBookmarksFile(file string) {
+	bookmarksFile = file
+}
+
+// GetBookmarks returns a copy of every bookmark, in the order they are cycled through.
+func GetBookmarks() []Bookmark {
+	return append([]Bookmark(nil), bookmarks...)
+}
+
+// SaveBookmark bookmarks where the active camera currently is under the provided name, replacing any bookmark which
+// already has that name, and writes every bookmark back to the bookmarks file if there is one.
+func SaveBookmark(name string) error {
+	c, ok := Active().(Posable)
+	if !ok {
+		return fmt.Errorf("the %s camera can't be bookmarked", ActiveName())
+	}
+	b := NewBookmark(name, c.GetViewpoint())
+	if i := findBookmark(name); i >= 0 {
+		bookmarks[i] = b
+		currentBookmark = i
+	} else {
+		bookmarks = append(bookmarks, b)
+		currentBookmark = len(bookmarks) - 1
+	}
+	if bookmarksFile == "" {
+		return nil
+	}
+	if err := SaveBookmarks(bookmarksFile); err != nil {
+		return fmt.Errorf("failed to save bookmarks %q: %v", bookmarksFile, err)
+	}
+	return nil
+}
+
+// JumpTo flies the active camera to the named bookmark over duration seconds.
+func JumpTo(name string, duration float64) error {
+	i := findBookmark(name)
+	if i < 0 {
+		return fmt.Errorf("unknown bookmark %q", name)
+	}
+	c, ok := Active().(Posable)
+	if !ok {
+		return fmt.Errorf("the %s camera can't jump to bookmarks", ActiveName())
+	}
+	currentBookmark = i
+	if duration <= 0 {
+		c.SetViewpoint(bookmarks[i].Viewpoint())
+		return nil
+	}
+	TransitionTo(c, bookmarks[i].Viewpoint(), duration)
+	return nil
+}
+
+// unusedBookmarkName returns the first of "Bookmark 1", "Bookmark 2" and so on which no bookmark is named yet.
+func unusedBookmarkName() string {
+	for n := 1; ; n++ {
+		if name := fmt.Sprintf("Bookmark %d", n); findBookmark(name) < 0 {
+			return name
+		}
+	}
+}
+
+func findBookmark(name string) int {
+	for i, b := range bookmarks {
+		if b.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// cycleBookmark jumps to the bookmark step places after the current one, wrapping around at either end.
+func cycleBookmark(step int) {
+	if len(bookmarks) == 0 {
+		return
+	}
+	i := ((currentBookmark+step)%len(bookmarks) + len(bookmarks)) % len(bookmarks)
+	if currentBookmark < 0 && step < 0 {
+		i = len(bookmarks) - 1
+	}
+	JumpTopic.Publish(Jump{bookmarks[i].Name, bookmarkTransitionTime})
+}
+
+func handleBookmarkActions(a input.ActionInput) {
+	if a.WasPressed("SaveBookmark") {
+		name := unusedBookmarkName()
+		if err := SaveBookmark(name); err != nil {
+			logf("failed to save bookmark: %v", err)
+		} else {
+			logf("saved %q", name)
+		}
+	}
+	if a.WasPressed("NextBookmark") {
+		cycleBookmark(1)
+	}
+	if a.WasPressed("PreviousBookmark") {
+		cycleBookmark(-1)
+	}
+	if a.WasPressed("RestoreBookmark") && currentBookmark >= 0 {
+		JumpTopic.Publish(Jump{bookmarks[currentBookmark].Name, bookmarkTransitionTime})
+	}
+}
+
+func handleJump(j Jump) {
+	if err := JumpTo(j.Bookmark, j.Duration); err != nil {
+		logf("failed to jump: %v", err)
+	}
+}
+
+func logf(format string, a ...interface{}) {
+	messagebus.SendAsync(&messagebus.Message{System: "Camera", Type: "log", Data1: fmt.Sprintf(format, a...)})
+}
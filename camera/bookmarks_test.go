@@ -0,0 +1,27 @@
+package camera
+
+import "testing"
+
+func TestUnusedBookmarkName(t *testing.T) {
+	saved := bookmarks
+	t.Cleanup(func() { bookmarks = saved })
+
+	for _, c := range []struct {
+		names []string
+		want  string
+	}{
+		{nil, "Bookmark 1"},
+		{[]string{"Bookmark 1", "Bookmark 2"}, "Bookmark 3"},
+		// Deleting or renaming one leaves a gap, which is reused rather than clashing with the last one.
+		{[]string{"Bookmark 1", "Bookmark 3"}, "Bookmark 2"},
+		{[]string{"Entrance", "Bookmark 2"}, "Bookmark 1"},
+	} {
+		bookmarks = nil
+		for _, name := range c.names {
+			bookmarks = append(bookmarks, Bookmark{Name: name})
+		}
+		if got := unusedBookmarkName(); got != c.want {
+			t.Errorf("with %v got %q, want %q", c.names, got, c.want)
+		}
+	}
+}
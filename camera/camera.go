@@ -0,0 +1,157 @@
+package camera
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/brandonnelson3/GameEngine/input"
+	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/brandonnelson3/GameEngine/window"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	pi2 = math.Pi / 2.0
+
+	// Zooming with the scroll wheel narrows or widens the field of view, in degrees, within these limits.
+	minFov       = 10.0
+	maxFov       = 90.0
+	fovPerScroll = 2.5
+
+	// lookSpeed is how fast, in radians per second, a fully deflected joystick turns a camera.
+	lookSpeed = 2.5
+)
+
+var (
+	cameras []namedCamera
+	active  = -1
+
+	up = mgl32.Vec3{0, 1, 0}
+)
+
+func init() {
+	input.ActionTopic.Subscribe(handleNextCamera)
+	input.ActionTopic.Subscribe(handleBookmarkActions)
+	JumpTopic.Subscribe(handleJump)
+}
+
+// Camera is anything the scene can be rendered from.
+type Camera interface {
+	// Activate subscribes this camera to input, so that it can be controlled.
+	Activate()
+	// Deactivate unsubscribes this camera from input, after which it no longer responds to any controls.
+	Deactivate()
+	// Update is called every frame to execute this frame's movement.
+	Update(d float64)
+	// GetView returns the current view matrix for this camera.
+	GetView() mgl32.Mat4
+	// GetProjection returns the current projection matrix for this camera.
+	GetProjection() mgl32.Mat4
+	// GetPosition returns the position of this camera.
+	GetPosition() mgl32.Vec3
+	// GetForward returns the forward unit vector for this camera.
+	GetForward() mgl32.Vec3
+}
+
+type namedCamera struct {
+	name   string
+	camera Camera
+}
+
+// Add adds a camera which can be switched to by name, or by cycling through cameras in the order they were added. The
+// first camera added becomes the active camera.
+func Add(name string, c Camera) {
+	cameras = append(cameras, namedCamera{name, c})
+	if active < 0 {
+		activate(0)
+	}
+}
+
+// Active returns the active camera, or nil if no camera has been added.
+func Active() Camera {
+	if active < 0 {
+		return nil
+	}
+	return cameras[active].camera
+}
+
+// ActiveName returns the name of the active camera.
+func ActiveName() string {
+	if active < 0 {
+		return ""
+	}
+	return cameras[active].name
+}
+
+// SetActive switches to the camera with the provided name.
+func SetActive(name string) error {
+	for i, c := range cameras {
+		if c.name == name {
+			activate(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown camera %q", name)
+}
+
+// Next switches to the camera which was added after the active one, wrapping around to the first.
+func Next() {
+	if len(cameras) == 0 {
+		return
+	}
+	activate((active + 1) % len(cameras))
+}
+
+// Update updates the active camera, and then advances every playing Animation. It is expected to be called once per
+// frame, after input has been published.
+func Update(d float64) {
+	if c := Active(); c != nil {
+		c.Update(d)
+	}
+	updateAnimations(d)
+}
+
+func activate(i int) {
+	if i == active {
+		return
+	}
+	// The previous camera is deactivated first, since it may share controls, or even a camera, with the next.
+	if c := Active(); c != nil {
+		c.Deactivate()
+	}
+	active = i
+	cameras[i].camera.Activate()
+	logf("switched to %s camera", cameras[i].name)
+}
+
+func handleNextCamera(a input.ActionInput) {
+	if a.WasPressed("NextCamera") {
+		Next()
+	}
+}
+
+// inputs tracks the subscriptions a camera holds while it is active.
+type inputs struct {
+	subscriptions []*messagebus.Subscription
+}
+
+func (in *inputs) subscribe(s ...*messagebus.Subscription) {
+	in.subscriptions = append(in.subscriptions, s...)
+}
+
+func (in *inputs) isActive() bool {
+	return len(in.subscriptions) > 0
+}
+
+func (in *inputs) unsubscribe() {
+	for _, s := range in.subscriptions {
+		s.Unsubscribe()
+	}
+	in.subscriptions = nil
+}
+
+// zoom narrows or widens the field of view shared by every camera.
+func zoom(scroll input.ScrollInput) {
+	window.SetFov(mgl32.Clamp(window.GetFov()-fovPerScroll*float32(scroll.Y), minFov, maxFov))
+}
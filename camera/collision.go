@@ -0,0 +1,78 @@
+package camera
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// AABB is an axis aligned bounding box.
+type AABB struct {
+	Min, Max mgl32.Vec3
+}
+
+// Collider is the static scene geometry a camera is kept out of, treating the camera as a sphere.
+type Collider struct {
+	Boxes []AABB
+	// HasGround is whether there is an infinite, horizontal ground plane at GroundHeight which nothing can go below.
+	HasGround    bool
+	GroundHeight float32
+}
+
+// Resolve pushes a sphere at position with the provided radius out of everything it overlaps, and removes any part of
+// its velocity which is heading into what it was pushed out of, so that it slides along surfaces rather than sticking to
+// them.
+func (c *Collider) Resolve(position, velocity mgl32.Vec3, radius float32) (mgl32.Vec3, mgl32.Vec3) {
+	if c.HasGround && position.Y()-radius < c.GroundHeight {
+		position[1] = c.GroundHeight + radius
+		velocity = slide(velocity, up)
+	}
+	for _, b := range c.Boxes {
+		normal, depth := b.penetration(position, radius)
+		if depth <= 0 {
+			continue
+		}
+		position = position.Add(normal.Mul(depth))
+		velocity = slide(velocity, normal)
+	}
+	return position, velocity
+}
+
+// penetration returns the direction a sphere must be pushed to leave this box, and how far, which is 0 if they don't
+// overlap.
+func (b AABB) penetration(center mgl32.Vec3, radius float32) (mgl32.Vec3, float32) {
+	var closest mgl32.Vec3
+	for i := range closest {
+		closest[i] = mgl32.Clamp(center[i], b.Min[i], b.Max[i])
+	}
+	diff := center.Sub(closest)
+	if d := diff.Len(); d > 0 {
+		if d >= radius {
+			return mgl32.Vec3{}, 0
+		}
+		return diff.Mul(1 / d), radius - d
+	}
+
+	// The center is inside the box, so push it out through whichever face is closest.
+	var normal mgl32.Vec3
+	depth := float32(-1)
+	for i := range center {
+		if d := center[i] - b.Min[i]; depth < 0 || d < depth {
+			depth = d
+			normal = mgl32.Vec3{}
+			normal[i] = -1
+		}
+		if d := b.Max[i] - center[i]; d < depth {
+			depth = d
+			normal = mgl32.Vec3{}
+			normal[i] = 1
+		}
+	}
+	return normal, depth + radius
+}
+
+// slide removes the part of velocity heading into a surface with the provided normal.
+func slide(velocity, normal mgl32.Vec3) mgl32.Vec3 {
+	if into := velocity.Dot(normal); into < 0 {
+		return velocity.Sub(normal.Mul(into))
+	}
+	return velocity
+}
@@ -0,0 +1,57 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestColliderResolve(t *testing.T) {
+	box := AABB{Min: mgl32.Vec3{0, 0, 0}, Max: mgl32.Vec3{2, 2, 2}}
+	boxOnly := &Collider{Boxes: []AABB{box}}
+	groundOnly := &Collider{HasGround: true}
+	both := &Collider{Boxes: []AABB{box}, HasGround: true}
+	const radius = 0.5
+	// corner is how far along each axis the sphere is pushed from the box's corner, to be radius away from it.
+	corner := float32(radius / math.Sqrt(3))
+
+	for _, c := range []struct {
+		name                       string
+		collider                   *Collider
+		position, velocity         mgl32.Vec3
+		wantPosition, wantVelocity mgl32.Vec3
+	}{
+		{"clear of everything", both, mgl32.Vec3{5, 5, 5}, mgl32.Vec3{1, -1, 0}, mgl32.Vec3{5, 5, 5}, mgl32.Vec3{1, -1, 0}},
+		{"touching a face", boxOnly, mgl32.Vec3{2.5, 1, 1}, mgl32.Vec3{-1, 0, 0}, mgl32.Vec3{2.5, 1, 1}, mgl32.Vec3{-1, 0, 0}},
+		{"below the ground", groundOnly, mgl32.Vec3{5, 0.2, 5}, mgl32.Vec3{1, -2, 0}, mgl32.Vec3{5, 0.5, 5}, mgl32.Vec3{1, 0, 0}},
+		{"leaving the ground", groundOnly, mgl32.Vec3{5, -3, 5}, mgl32.Vec3{0, 1, 0}, mgl32.Vec3{5, 0.5, 5}, mgl32.Vec3{0, 1, 0}},
+		{"into a face", boxOnly, mgl32.Vec3{2.3, 1, 1}, mgl32.Vec3{-1, 1, 0}, mgl32.Vec3{2.5, 1, 1}, mgl32.Vec3{0, 1, 0}},
+		{"away from a face", boxOnly, mgl32.Vec3{1, 1, -0.4}, mgl32.Vec3{0, 0, -1}, mgl32.Vec3{1, 1, -0.5}, mgl32.Vec3{0, 0, -1}},
+		{
+			"into an edge", boxOnly,
+			mgl32.Vec3{2.3, 2.3, 1}, mgl32.Vec3{-1, -1, 0},
+			mgl32.Vec3{2 + radius/math.Sqrt2, 2 + radius/math.Sqrt2, 1}, mgl32.Vec3{0, 0, 0},
+		},
+		{
+			"into a corner", boxOnly,
+			mgl32.Vec3{2.2, 2.2, 2.2}, mgl32.Vec3{-1, 0, 0},
+			mgl32.Vec3{2 + corner, 2 + corner, 2 + corner}, mgl32.Vec3{-2.0 / 3, 1.0 / 3, 1.0 / 3},
+		},
+		// Once the center is inside the box, it is pushed out through the nearest face, along the axis it has gone the
+		// least far in on.
+		{"center inside near +x", boxOnly, mgl32.Vec3{1.8, 1, 1.1}, mgl32.Vec3{-1, 0, 0}, mgl32.Vec3{2.5, 1, 1.1}, mgl32.Vec3{0, 0, 0}},
+		{"center inside near -y", boxOnly, mgl32.Vec3{0.9, 0.1, 1.2}, mgl32.Vec3{0, 1, 1}, mgl32.Vec3{0.9, -0.5, 1.2}, mgl32.Vec3{0, 0, 1}},
+		{"center inside near +z", boxOnly, mgl32.Vec3{1.1, 0.8, 1.95}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{1.1, 0.8, 2.5}, mgl32.Vec3{0, 0, 0}},
+		// The ground is resolved first, and then the box beside it.
+		{"between the ground and a box", both, mgl32.Vec3{2.3, 0.2, 1}, mgl32.Vec3{-1, -1, 1}, mgl32.Vec3{2.5, 0.5, 1}, mgl32.Vec3{0, 0, 1}},
+	} {
+		position, velocity := c.collider.Resolve(c.position, c.velocity, radius)
+		if !near(position, c.wantPosition) {
+			t.Errorf("%s: pushed to %v, want %v", c.name, position, c.wantPosition)
+		}
+		if !near(velocity, c.wantVelocity) {
+			t.Errorf("%s: velocity became %v, want %v", c.name, velocity, c.wantVelocity)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/brandonnelson3/GameEngine/input"
+	"github.com/brandonnelson3/GameEngine/window"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// sprintMultiplier is how much faster a FirstPersonCamera moves while sprinting.
+	sprintMultiplier = 2.5
+	// acceleration and damping are how quickly, per second, a FirstPersonCamera's velocity closes the gap to the
+	// velocity it is being steered towards, while moving and while coming to a stop respectively.
+	acceleration = 8.0
+	damping      = 6.0
+	// minimumSpeed is the speed below which a FirstPersonCamera which isn't being steered stops entirely.
+	minimumSpeed = 0.01
+)
+
+// FirstPersonCamera is a camera which behaves like a FirstPersonShooter Camera would. WASD or the left stick control
+// the movement, speeding up and slowing down smoothly, and the mouse or the right stick controls the direction.
+type FirstPersonCamera struct {
+	inputs
+
+	position    mgl32.Vec3
+	orientation mgl32.Quat
+	velocity    mgl32.Vec3
+	direction   mgl32.Vec3
+	sprinting   bool
+	look        mgl32.Vec2
+	sensitivity float32
+	speed       float32
+
+	collider *Collider
+	radius   float32
+}
+
+// NewFirstPersonCamera instantiates a new FirstPersonCamera.
+func NewFirstPersonCamera() *FirstPersonCamera {
+	return &FirstPersonCamera{position: mgl32.Vec3{-22.585495, 22.307711, -21.923943}, orientation: OrientationFromAngles(5.506999, -0.476000), sensitivity: 0.001, speed: 20}
+}
+
+// Activate subscribes this camera to the keyboard, mouse and joystick.
+func (c *FirstPersonCamera) Activate() {
+	if c.isActive() {
+		return
+	}
+	c.subscribe(
+		input.ActionTopic.Subscribe(c.handleMovement),
+		input.MouseDeltaTopic.Subscribe(c.handleMouse),
+		input.ScrollTopic.Subscribe(c.handleScroll))
+}
+
+// Deactivate unsubscribes this camera from all input, after which it no longer responds to the keyboard or mouse.
+func (c *FirstPersonCamera) Deactivate() {
+	c.unsubscribe()
+	c.direction = mgl32.Vec3{0, 0, 0}
+	c.velocity = mgl32.Vec3{0, 0, 0}
+	c.sprinting = false
+	c.look = mgl32.Vec2{0, 0}
+}
+
+// SetCollider keeps this camera, as a sphere with the provided radius, out of the collider's geometry. Passing nil lets
+// it fly through everything.
+func (c *FirstPersonCamera) SetCollider(collider *Collider, radius float32) {
+	c.collider = collider
+	c.radius = radius
+}
+
+// Update is called every frame to execute this frame's movement.
+func (c *FirstPersonCamera) Update(d float64) {
+	if c.look.X() != 0 || c.look.Y() != 0 {
+		c.rotate(c.look.X()*lookSpeed*float32(d), c.look.Y()*lookSpeed*float32(d))
+		c.look = mgl32.Vec2{0, 0}
+	}
+
+	// A joystick which is only partially pushed moves slower, but moving diagonally is never faster than straight.
+	target := c.direction
+	if target.Len() > 1 {
+		target = target.Normalize()
+	}
+	target = target.Mul(c.speed)
+	if c.sprinting {
+		target = target.Mul(sprintMultiplier)
+	}
+	rate := acceleration
+	if target.Len() == 0 {
+		rate = damping
+	}
+	// Closing a fixed fraction of the gap per second, rather than per frame, behaves the same at any frame rate.
+	c.velocity = c.velocity.Add(target.Sub(c.velocity).Mul(float32(1 - math.Exp(-rate*d))))
+	if target.Len() == 0 && c.velocity.Len() < minimumSpeed {
+		c.velocity = mgl32.Vec3{0, 0, 0}
+	}
+	c.direction = mgl32.Vec3{0, 0, 0}
+	c.sprinting = false
+
+	if c.velocity.Len() == 0 {
+		return
+	}
+	if c.collider == nil {
+		c.position = c.position.Add(c.velocity.Mul(float32(d)))
+		return
+	}
+	// Moving in steps no longer than the radius means a fast camera can't pass straight through something thin.
+	steps := 1
+	if c.radius > 0 {
+		steps = int(math.Max(1, math.Ceil(float64(c.velocity.Len()*float32(d)/c.radius))))
+	}
+	for i := 0; i < steps; i++ {
+		c.position = c.position.Add(c.velocity.Mul(float32(d) / float32(steps)))
+		c.position, c.velocity = c.collider.Resolve(c.position, c.velocity, c.radius)
+	}
+}
+
+// GetPosition returns the position of this FirstPersonCamera.
+func (c *FirstPersonCamera) GetPosition() mgl32.Vec3 {
+	return c.position
+}
+
+// GetOrientation returns the orientation of this FirstPersonCamera.
+func (c *FirstPersonCamera) GetOrientation() mgl32.Quat {
+	return c.orientation
+}
+
+// GetViewpoint returns where this camera currently is and which way it is facing.
+func (c *FirstPersonCamera) GetViewpoint() Viewpoint {
+	return Viewpoint{c.position, c.orientation}
+}
+
+// SetViewpoint moves and turns this camera to the provided Viewpoint.
+func (c *FirstPersonCamera) SetViewpoint(v Viewpoint) {
+	c.position = v.Position
+	c.orientation = v.Orientation.Normalize()
+	c.velocity = mgl32.Vec3{0, 0, 0}
+}
+
+// GetVelocity returns the velocity of this FirstPersonCamera, in units per second.
+func (c *FirstPersonCamera) GetVelocity() mgl32.Vec3 {
+	return c.velocity
+}
+
+// GetForward returns the forward unit vector for this camera.
+func (c *FirstPersonCamera) GetForward() mgl32.Vec3 {
+	return c.orientation.Rotate(localForward)
+}
+
+// GetRight returns the right unit vector for this camera.
+func (c *FirstPersonCamera) GetRight() mgl32.Vec3 {
+	return c.orientation.Rotate(localRight)
+}
+
+// GetUp returns the up unit vector for this camera.
+func (c *FirstPersonCamera) GetUp() mgl32.Vec3 {
+	return c.orientation.Rotate(localUp)
+}
+
+// GetView returns the current view matrix for this camera.
+func (c *FirstPersonCamera) GetView() mgl32.Mat4 {
+	return mgl32.LookAtV(c.position, c.position.Add(c.GetForward()), c.GetUp())
+}
+
+// GetProjection returns the current projection matrix for this camera.
+func (c *FirstPersonCamera) GetProjection() mgl32.Mat4 {
+	return window.GetProjection()
+}
+
+func (c *FirstPersonCamera) handleMovement(a input.ActionInput) {
+	c.direction = c.GetForward().Mul(a.Axis("MoveForward")).Add(c.GetRight().Mul(a.Axis("MoveRight")))
+	c.sprinting = a.IsHeld("Sprint")
+	c.look = mgl32.Vec2{a.Axis("LookRight"), a.Axis("LookUp")}
+}
+
+func (c *FirstPersonCamera) handleMouse(d input.MouseDelta) {
+	c.rotate(c.sensitivity*float32(d.X), -c.sensitivity*float32(d.Y))
+}
+
+// rotate turns the camera right and up by the provided angles, in radians, without letting it look past straight up or
+// down. Turning right is around the world's up rather than the camera's, so that looking around never introduces roll.
+func (c *FirstPersonCamera) rotate(yaw, pitch float32) {
+	current := float32(math.Asin(float64(mgl32.Clamp(c.GetForward().Y(), -1, 1))))
+	pitch = mgl32.Clamp(current+pitch, float32(-pi2+0.0001), float32(pi2-0.0001)) - current
+	c.orientation = mgl32.QuatRotate(-yaw, up).Mul(c.orientation).Mul(mgl32.QuatRotate(pitch, localRight)).Normalize()
+}
+
+func (c *FirstPersonCamera) handleScroll(scroll input.ScrollInput) {
+	zoom(scroll)
+}
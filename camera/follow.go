@@ -0,0 +1,103 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/brandonnelson3/GameEngine/window"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// maxFollowStep is the longest step, in seconds, the follow spring is simulated over. Longer frames are split into
+	// several steps so that a stiff spring can't overshoot and explode.
+	maxFollowStep = 1.0 / 120.0
+)
+
+// FollowCamera is a camera which trails behind another camera, such as a FirstPersonCamera standing in for a player, and
+// looks at it. It is pulled towards its place behind the target by a damped spring, so it lags behind sudden movement
+// rather than being rigidly attached. While active, input controls the target.
+type FollowCamera struct {
+	target Camera
+
+	distance, height float32
+	// stiffness is the spring constant pulling the camera into place, and damping resists its velocity. The spring is
+	// critically damped when damping is 2*sqrt(stiffness).
+	stiffness, damping float32
+
+	position, velocity mgl32.Vec3
+	placed             bool
+}
+
+// NewFollowCamera instantiates a new FollowCamera which trails distance behind target, and height above it.
+func NewFollowCamera(target Camera, distance, height float32) *FollowCamera {
+	stiffness := float32(40)
+	return &FollowCamera{target: target, distance: distance, height: height, stiffness: stiffness, damping: 2 * float32(math.Sqrt(float64(stiffness)))}
+}
+
+// SetSpring sets the stiffness and damping of the spring pulling this camera into place.
+func (c *FollowCamera) SetSpring(stiffness, damping float32) {
+	c.stiffness, c.damping = stiffness, damping
+}
+
+// Activate subscribes the target to input, and snaps this camera into place behind it.
+func (c *FollowCamera) Activate() {
+	c.target.Activate()
+	c.placed = false
+}
+
+// Deactivate unsubscribes the target from input.
+func (c *FollowCamera) Deactivate() {
+	c.target.Deactivate()
+}
+
+// Update moves the target, and then springs this camera towards its place behind the target.
+func (c *FollowCamera) Update(d float64) {
+	c.target.Update(d)
+
+	desired := c.desiredPosition()
+	if !c.placed {
+		c.position = desired
+		c.velocity = mgl32.Vec3{0, 0, 0}
+		c.placed = true
+		return
+	}
+	for d > 0 {
+		step := float32(math.Min(d, maxFollowStep))
+		acceleration := desired.Sub(c.position).Mul(c.stiffness).Sub(c.velocity.Mul(c.damping))
+		c.velocity = c.velocity.Add(acceleration.Mul(step))
+		c.position = c.position.Add(c.velocity.Mul(step))
+		d -= maxFollowStep
+	}
+}
+
+// desiredPosition is where the spring is pulling this camera to, which is behind the direction the target is facing
+// along the ground, so that the target looking up or down doesn't swing the camera underneath it.
+func (c *FollowCamera) desiredPosition() mgl32.Vec3 {
+	forward := c.target.GetForward()
+	behind := mgl32.Vec3{-forward.X(), 0, -forward.Z()}
+	if behind.Len() > 0 {
+		behind = behind.Normalize()
+	}
+	return c.target.GetPosition().Add(behind.Mul(c.distance)).Add(up.Mul(c.height))
+}
+
+// GetPosition returns the position of this FollowCamera.
+func (c *FollowCamera) GetPosition() mgl32.Vec3 {
+	return c.position
+}
+
+// GetForward returns the forward unit vector for this camera, which always points at the target.
+func (c *FollowCamera) GetForward() mgl32.Vec3 {
+	return c.target.GetPosition().Sub(c.position).Normalize()
+}
+
+// GetView returns the current view matrix for this camera.
+func (c *FollowCamera) GetView() mgl32.Mat4 {
+	return mgl32.LookAtV(c.position, c.target.GetPosition(), up)
+}
+
+// GetProjection returns the current projection matrix for this camera.
+func (c *FollowCamera) GetProjection() mgl32.Mat4 {
+	return window.GetProjection()
+}
@@ -0,0 +1,145 @@
+package camera
+
+import (
+	"github.com/brandonnelson3/GameEngine/input"
+	"github.com/brandonnelson3/GameEngine/window"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// rollSpeed is how fast, in radians per second, a FreeFlyCamera rolls while its roll axis is fully deflected.
+	rollSpeed = 1.5
+)
+
+var (
+	// These are the directions a camera faces in its own space, before its orientation is applied.
+	localForward = mgl32.Vec3{0, 0, -1}
+	localRight   = mgl32.Vec3{1, 0, 0}
+	localUp      = mgl32.Vec3{0, 1, 0}
+)
+
+// FreeFlyCamera is a camera with six degrees of freedom, like a spacecraft. It turns and moves relative to its own
+// orientation rather than the ground, so it can look straight up or down, and can roll.
+type FreeFlyCamera struct {
+	inputs
+
+	position    mgl32.Vec3
+	orientation mgl32.Quat
+	sensitivity float32
+	speed       float32
+
+	direction mgl32.Vec3
+	look      mgl32.Vec2
+	roll      float32
+}
+
+// NewFreeFlyCamera instantiates a new FreeFlyCamera at the provided position and orientation.
+func NewFreeFlyCamera(position mgl32.Vec3, orientation mgl32.Quat) *FreeFlyCamera {
+	return &FreeFlyCamera{position: position, orientation: orientation.Normalize(), sensitivity: 0.001, speed: 20}
+}
+
+// Activate subscribes this camera to the keyboard, mouse and joystick.
+func (c *FreeFlyCamera) Activate() {
+	if c.isActive() {
+		return
+	}
+	c.subscribe(
+		input.ActionTopic.Subscribe(c.handleMovement),
+		input.MouseDeltaTopic.Subscribe(c.handleMouse),
+		input.ScrollTopic.Subscribe(c.handleScroll))
+}
+
+// Deactivate unsubscribes this camera from all input.
+func (c *FreeFlyCamera) Deactivate() {
+	c.unsubscribe()
+	c.direction = mgl32.Vec3{0, 0, 0}
+	c.look = mgl32.Vec2{0, 0}
+	c.roll = 0
+}
+
+// Update is called every frame to execute this frame's movement.
+func (c *FreeFlyCamera) Update(d float64) {
+	if c.look.X() != 0 || c.look.Y() != 0 || c.roll != 0 {
+		c.rotate(c.look.X()*lookSpeed*float32(d), c.look.Y()*lookSpeed*float32(d), c.roll*rollSpeed*float32(d))
+		c.look = mgl32.Vec2{0, 0}
+		c.roll = 0
+	}
+	if c.direction.X() != 0 || c.direction.Y() != 0 || c.direction.Z() != 0 {
+		direction := c.direction
+		if direction.Len() > 1 {
+			direction = direction.Normalize()
+		}
+		c.position = c.position.Add(direction.Mul(float32(d) * c.speed))
+		c.direction = mgl32.Vec3{0, 0, 0}
+	}
+}
+
+// GetPosition returns the position of this FreeFlyCamera.
+func (c *FreeFlyCamera) GetPosition() mgl32.Vec3 {
+	return c.position
+}
+
+// GetOrientation returns the orientation of this FreeFlyCamera.
+func (c *FreeFlyCamera) GetOrientation() mgl32.Quat {
+	return c.orientation
+}
+
+// GetViewpoint returns where this camera currently is and which way it is facing.
+func (c *FreeFlyCamera) GetViewpoint() Viewpoint {
+	return Viewpoint{c.position, c.orientation}
+}
+
+// SetViewpoint moves and turns this camera to the provided Viewpoint.
+func (c *FreeFlyCamera) SetViewpoint(v Viewpoint) {
+	c.position = v.Position
+	c.orientation = v.Orientation.Normalize()
+}
+
+// GetForward returns the forward unit vector for this camera.
+func (c *FreeFlyCamera) GetForward() mgl32.Vec3 {
+	return c.orientation.Rotate(localForward)
+}
+
+// GetRight returns the right unit vector for this camera.
+func (c *FreeFlyCamera) GetRight() mgl32.Vec3 {
+	return c.orientation.Rotate(localRight)
+}
+
+// GetUp returns the up unit vector for this camera, which tilts as the camera rolls.
+func (c *FreeFlyCamera) GetUp() mgl32.Vec3 {
+	return c.orientation.Rotate(localUp)
+}
+
+// GetView returns the current view matrix for this camera.
+func (c *FreeFlyCamera) GetView() mgl32.Mat4 {
+	return mgl32.LookAtV(c.position, c.position.Add(c.GetForward()), c.GetUp())
+}
+
+// GetProjection returns the current projection matrix for this camera.
+func (c *FreeFlyCamera) GetProjection() mgl32.Mat4 {
+	return window.GetProjection()
+}
+
+func (c *FreeFlyCamera) handleMovement(a input.ActionInput) {
+	c.direction = c.GetForward().Mul(a.Axis("MoveForward")).Add(c.GetRight().Mul(a.Axis("MoveRight"))).Add(c.GetUp().Mul(a.Axis("MoveUp")))
+	c.look = mgl32.Vec2{a.Axis("LookRight"), a.Axis("LookUp")}
+	c.roll = a.Axis("Roll")
+}
+
+func (c *FreeFlyCamera) handleMouse(d input.MouseDelta) {
+	c.rotate(c.sensitivity*float32(d.X), -c.sensitivity*float32(d.Y), 0)
+}
+
+func (c *FreeFlyCamera) handleScroll(scroll input.ScrollInput) {
+	zoom(scroll)
+}
+
+// rotate turns the camera right, up and clockwise by the provided angles, in radians, about its own axes.
+func (c *FreeFlyCamera) rotate(yaw, pitch, roll float32) {
+	c.orientation = c.orientation.
+		Mul(mgl32.QuatRotate(-yaw, localUp)).
+		Mul(mgl32.QuatRotate(pitch, localRight)).
+		Mul(mgl32.QuatRotate(roll, localForward)).
+		Normalize()
+}
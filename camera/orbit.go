@@ -0,0 +1,133 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/brandonnelson3/GameEngine/input"
+	"github.com/brandonnelson3/GameEngine/window"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// An OrbitCamera can't be scrolled closer to or further from its target than these distances.
+	minOrbitDistance = 1.0
+	maxOrbitDistance = 500.0
+	// orbitZoomPerScroll is the fraction of the distance which is kept for every step the scroll wheel is moved up.
+	orbitZoomPerScroll = 0.9
+)
+
+// OrbitCamera is a camera which always looks at a target point and orbits around it, like an arcball. The mouse or the
+// right stick orbit around the target, the scroll wheel moves towards or away from it, and WASD or the left stick pan the
+// target along the ground.
+type OrbitCamera struct {
+	inputs
+
+	target      mgl32.Vec3
+	distance    float32
+	yaw, pitch  float32
+	sensitivity float32
+	speed       float32
+
+	pan  mgl32.Vec3
+	look mgl32.Vec2
+}
+
+// NewOrbitCamera instantiates a new OrbitCamera looking at target from the provided distance.
+func NewOrbitCamera(target mgl32.Vec3, distance float32) *OrbitCamera {
+	return &OrbitCamera{target: target, distance: distance, yaw: math.Pi / 4, pitch: math.Pi / 6, sensitivity: 0.005, speed: 20}
+}
+
+// Activate subscribes this camera to the keyboard, mouse and joystick.
+func (c *OrbitCamera) Activate() {
+	if c.isActive() {
+		return
+	}
+	c.subscribe(
+		input.ActionTopic.Subscribe(c.handleMovement),
+		input.MouseDeltaTopic.Subscribe(c.handleMouse),
+		input.ScrollTopic.Subscribe(c.handleScroll))
+}
+
+// Deactivate unsubscribes this camera from all input.
+func (c *OrbitCamera) Deactivate() {
+	c.unsubscribe()
+	c.pan = mgl32.Vec3{0, 0, 0}
+	c.look = mgl32.Vec2{0, 0}
+}
+
+// Update is called every frame to execute this frame's movement.
+func (c *OrbitCamera) Update(d float64) {
+	if c.look.X() != 0 || c.look.Y() != 0 {
+		c.orbit(c.look.X()*lookSpeed*float32(d), c.look.Y()*lookSpeed*float32(d))
+		c.look = mgl32.Vec2{0, 0}
+	}
+	if c.pan.X() != 0 || c.pan.Z() != 0 {
+		pan := c.pan
+		if pan.Len() > 1 {
+			pan = pan.Normalize()
+		}
+		// Panning is faster when zoomed out, so the target moves across the screen at roughly the same rate.
+		c.target = c.target.Add(pan.Mul(float32(d) * c.speed * c.distance / 20))
+		c.pan = mgl32.Vec3{0, 0, 0}
+	}
+}
+
+// SetTarget moves the point this camera orbits around.
+func (c *OrbitCamera) SetTarget(target mgl32.Vec3) {
+	c.target = target
+}
+
+// GetTarget returns the point this camera orbits around.
+func (c *OrbitCamera) GetTarget() mgl32.Vec3 {
+	return c.target
+}
+
+// GetPosition returns the position of this OrbitCamera.
+func (c *OrbitCamera) GetPosition() mgl32.Vec3 {
+	offset := mgl32.Vec3{
+		float32(math.Cos(float64(c.pitch)) * math.Cos(float64(c.yaw))),
+		float32(math.Sin(float64(c.pitch))),
+		float32(math.Cos(float64(c.pitch)) * math.Sin(float64(c.yaw))),
+	}
+	return c.target.Add(offset.Mul(c.distance))
+}
+
+// GetForward returns the forward unit vector for this camera, which always points at the target.
+func (c *OrbitCamera) GetForward() mgl32.Vec3 {
+	return c.target.Sub(c.GetPosition()).Normalize()
+}
+
+// GetView returns the current view matrix for this camera.
+func (c *OrbitCamera) GetView() mgl32.Mat4 {
+	return mgl32.LookAtV(c.GetPosition(), c.target, up)
+}
+
+// GetProjection returns the current projection matrix for this camera.
+func (c *OrbitCamera) GetProjection() mgl32.Mat4 {
+	return window.GetProjection()
+}
+
+func (c *OrbitCamera) handleMovement(a input.ActionInput) {
+	// Panning follows the view, but stays level with the ground.
+	forward := c.GetForward()
+	forward = mgl32.Vec3{forward.X(), 0, forward.Z()}.Normalize()
+	right := forward.Cross(up)
+	c.pan = forward.Mul(a.Axis("MoveForward")).Add(right.Mul(a.Axis("MoveRight")))
+	c.look = mgl32.Vec2{a.Axis("LookRight"), a.Axis("LookUp")}
+}
+
+func (c *OrbitCamera) handleMouse(d input.MouseDelta) {
+	c.orbit(c.sensitivity*float32(d.X), -c.sensitivity*float32(d.Y))
+}
+
+func (c *OrbitCamera) handleScroll(scroll input.ScrollInput) {
+	c.distance = mgl32.Clamp(c.distance*float32(math.Pow(orbitZoomPerScroll, scroll.Y)), minOrbitDistance, maxOrbitDistance)
+}
+
+// orbit moves the camera around the target by the provided angles, in radians, without letting it pass over the top or
+// bottom of the target.
+func (c *OrbitCamera) orbit(yaw, pitch float32) {
+	c.yaw += yaw
+	c.pitch = mgl32.Clamp(c.pitch-pitch, float32(-pi2+0.01), float32(pi2-0.01))
+}
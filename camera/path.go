@@ -0,0 +1,186 @@
+package camera
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SplineType is how a Path curves between its keyframes.
+type SplineType string
+
+const (
+	// CatmullRom passes smoothly through every keyframe, curving based on the keyframes either side.
+	CatmullRom SplineType = "catmullrom"
+	// Bezier curves between each pair of keyframes towards their Out and In control points.
+	Bezier SplineType = "bezier"
+)
+
+// PathKeyframe is a point a Path passes through, as stored in a path file.
+type PathKeyframe struct {
+	// Time is when the path passes through this keyframe, in seconds since the path started.
+	Time float64 `json:"time"`
+	// Bookmark, if it is set, is the name of a bookmark to take the position and orientation from.
+	Bookmark    string     `json:"bookmark,omitempty"`
+	Position    mgl32.Vec3 `json:"position"`
+	Orientation [4]float32 `json:"orientation"`
+	// Fov is the field of view in degrees. Either every keyframe sets it, or none do and it is left alone.
+	Fov float32 `json:"fov,omitempty"`
+	// In and Out are the Bezier control points the path curves towards on its way into and out of this keyframe. They
+	// default to a third of the way along the straight line to the neighbouring keyframes, and are ignored by CatmullRom.
+	In  *mgl32.Vec3 `json:"in,omitempty"`
+	Out *mgl32.Vec3 `json:"out,omitempty"`
+}
+
+// Path is a Track which follows a spline through a series of keyframes, for scripted fly-throughs. Positions and fields
+// of view follow the spline, and orientations are spherically interpolated between each pair of keyframes.
+type Path struct {
+	Spline    SplineType     `json:"spline"`
+	Keyframes []PathKeyframe `json:"keyframes"`
+
+	viewpoints []Viewpoint
+}
+
+// LoadPath reads a Path from the provided JSON path file. Keyframes which name a bookmark are resolved against the
+// bookmarks loaded at the time.
+func LoadPath(file string) (*Path, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read path %q: %v", file, err)
+	}
+	p := &Path{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse path %q: %v", file, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid path %q: %v", file, err)
+	}
+	return p, nil
+}
+
+// Validate checks this Path can be played, and resolves any bookmarks its keyframes name. It must be called before a
+// Path which wasn't loaded by LoadPath is played.
+func (p *Path) Validate() error {
+	switch p.Spline {
+	case CatmullRom, Bezier:
+	default:
+		return fmt.Errorf("unknown spline %q", p.Spline)
+	}
+	if len(p.Keyframes) < 2 {
+		return fmt.Errorf("at least 2 keyframes are needed, but there are %d", len(p.Keyframes))
+	}
+	p.viewpoints = make([]Viewpoint, len(p.Keyframes))
+	for i, k := range p.Keyframes {
+		if i > 0 && k.Time <= p.Keyframes[i-1].Time {
+			return fmt.Errorf("keyframe %d is at %vs, which isn't after the previous keyframe", i, k.Time)
+		}
+		if (k.Fov > 0) != (p.Keyframes[0].Fov > 0) {
+			return fmt.Errorf("keyframe %d must set fov if, and only if, every other keyframe does", i)
+		}
+		if k.Bookmark != "" {
+			j := findBookmark(k.Bookmark)
+			if j < 0 {
+				return fmt.Errorf("keyframe %d: unknown bookmark %q", i, k.Bookmark)
+			}
+			p.viewpoints[i] = bookmarks[j].Viewpoint()
+			continue
+		}
+		if k.Orientation == [4]float32{} {
+			return fmt.Errorf("keyframe %d has no orientation", i)
+		}
+		p.viewpoints[i] = Bookmark{Position: k.Position, Orientation: k.Orientation}.Viewpoint()
+	}
+	return nil
+}
+
+// Duration returns the time of the last keyframe.
+func (p *Path) Duration() float64 {
+	return p.Keyframes[len(p.Keyframes)-1].Time
+}
+
+// Sample returns the Viewpoint t seconds after the first keyframe.
+func (p *Path) Sample(t float64) Viewpoint {
+	i, u := p.segment(t)
+	v := Interpolate(p.viewpoints[i], p.viewpoints[i+1], u)
+	switch p.Spline {
+	case Bezier:
+		v.Position = p.bezier(i, u)
+	default:
+		v.Position = hermite(p.position(i), p.position(i+1), p.tangent(i, p.position), p.tangent(i+1, p.position), p.span(i), u)
+	}
+	return v
+}
+
+// SampleFov returns the field of view t seconds after the first keyframe, if the keyframes set it.
+func (p *Path) SampleFov(t float64) (float32, bool) {
+	if p.Keyframes[0].Fov <= 0 {
+		return 0, false
+	}
+	i, u := p.segment(t)
+	if p.Spline == Bezier {
+		return p.Keyframes[i].Fov + (p.Keyframes[i+1].Fov-p.Keyframes[i].Fov)*u, true
+	}
+	fov := func(i int) mgl32.Vec3 { return mgl32.Vec3{p.Keyframes[i].Fov, 0, 0} }
+	return hermite(fov(i), fov(i+1), p.tangent(i, fov), p.tangent(i+1, fov), p.span(i), u).X(), true
+}
+
+// segment returns the keyframe t seconds into the path is after, and how far it is from there to the next keyframe.
+func (p *Path) segment(t float64) (int, float32) {
+	last := len(p.Keyframes) - 2
+	for i := 0; i <= last; i++ {
+		if t < p.Keyframes[i+1].Time || i == last {
+			u := (t - p.Keyframes[i].Time) / p.span(i)
+			return i, mgl32.Clamp(float32(u), 0, 1)
+		}
+	}
+	return last, 1
+}
+
+func (p *Path) span(i int) float64 {
+	return p.Keyframes[i+1].Time - p.Keyframes[i].Time
+}
+
+func (p *Path) position(i int) mgl32.Vec3 {
+	return p.viewpoints[i].Position
+}
+
+// tangent returns the velocity, per second, of value through keyframe i. Using the keyframes either side, rather than
+// assuming they are evenly spaced in time, keeps the speed continuous through keyframes with uneven gaps between them.
+func (p *Path) tangent(i int, value func(int) mgl32.Vec3) mgl32.Vec3 {
+	before, after := i-1, i+1
+	if before < 0 {
+		before = i
+	}
+	if after >= len(p.Keyframes) {
+		after = i
+	}
+	return value(after).Sub(value(before)).Mul(float32(1 / (p.Keyframes[after].Time - p.Keyframes[before].Time)))
+}
+
+// bezier returns the position u of the way along the cubic Bezier curve from keyframe i to keyframe i+1.
+func (p *Path) bezier(i int, u float32) mgl32.Vec3 {
+	p0, p3 := p.position(i), p.position(i+1)
+	p1 := p0.Add(p3.Sub(p0).Mul(1.0 / 3))
+	if out := p.Keyframes[i].Out; out != nil {
+		p1 = *out
+	}
+	p2 := p3.Sub(p3.Sub(p0).Mul(1.0 / 3))
+	if in := p.Keyframes[i+1].In; in != nil {
+		p2 = *in
+	}
+	v := 1 - u
+	return p0.Mul(v * v * v).Add(p1.Mul(3 * v * v * u)).Add(p2.Mul(3 * v * u * u)).Add(p3.Mul(u * u * u))
+}
+
+// hermite returns the point u of the way along the cubic Hermite curve from p0 to p1, leaving with velocity m0 and
+// arriving with velocity m1, over span seconds.
+func hermite(p0, p1, m0, m1 mgl32.Vec3, span float64, u float32) mgl32.Vec3 {
+	u2, u3 := u*u, u*u*u
+	s := float32(span)
+	return p0.Mul(2*u3 - 3*u2 + 1).
+		Add(m0.Mul((u3 - 2*u2 + u) * s)).
+		Add(p1.Mul(-2*u3 + 3*u2)).
+		Add(m1.Mul((u3 - u2) * s))
+}
@@ -0,0 +1,121 @@
+package camera
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// identity is an orientation as it is stored in path and bookmark files.
+var identity = [4]float32{1, 0, 0, 0}
+
+// newPath returns a validated Path through keyframes at the provided times and positions.
+func newPath(t *testing.T, spline SplineType, times []float64, positions []mgl32.Vec3) *Path {
+	p := &Path{Spline: spline}
+	for i := range times {
+		p.Keyframes = append(p.Keyframes, PathKeyframe{Time: times[i], Position: positions[i], Orientation: identity})
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPathPassesThroughItsKeyframes(t *testing.T) {
+	times := []float64{0, 1, 3.5}
+	positions := []mgl32.Vec3{{0, 0, 0}, {2, 5, -1}, {-3, 1, 4}}
+	for _, spline := range []SplineType{CatmullRom, Bezier} {
+		p := newPath(t, spline, times, positions)
+		// The Bezier control points don't lie on the path, so the curve must still end up at the keyframes.
+		p.Keyframes[0].Out = &mgl32.Vec3{10, 10, 10}
+		p.Keyframes[1].In = &mgl32.Vec3{-10, 0, 3}
+
+		for i, time := range times {
+			if got := p.Sample(time).Position; got != positions[i] {
+				t.Errorf("%s path is at %v at %vs, want exactly %v", spline, got, time, positions[i])
+			}
+		}
+	}
+}
+
+func TestPathKeepsItsSpeedThroughUnevenKeyframes(t *testing.T) {
+	// The keyframes are 1s and then 3s apart, but evenly spaced in distance for the time, so the path moves at a constant
+	// 1 unit per second throughout.
+	p := newPath(t, CatmullRom, []float64{0, 1, 4}, []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {4, 0, 0}})
+
+	for _, time := range []float64{0.25, 0.5, 0.99, 1.01, 2, 2.5, 3.75} {
+		want := mgl32.Vec3{float32(time), 0, 0}
+		if got := p.Sample(time).Position; !near(got, want) {
+			t.Errorf("at %vs the path is at %v, want %v", time, got, want)
+		}
+	}
+}
+
+func TestPathClampsToItsEnds(t *testing.T) {
+	first, last := mgl32.Vec3{1, 2, 3}, mgl32.Vec3{7, 8, 9}
+	for _, spline := range []SplineType{CatmullRom, Bezier} {
+		p := newPath(t, spline, []float64{1, 2, 3}, []mgl32.Vec3{first, {4, 4, 4}, last})
+		p.Keyframes[2].Orientation = [4]float32{0, 0, 1, 0}
+		if err := p.Validate(); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, c := range []struct {
+			time float64
+			want Viewpoint
+		}{
+			{0, Bookmark{Position: first, Orientation: identity}.Viewpoint()},
+			{-5, Bookmark{Position: first, Orientation: identity}.Viewpoint()},
+			{4, Bookmark{Position: last, Orientation: p.Keyframes[2].Orientation}.Viewpoint()},
+			{100, Bookmark{Position: last, Orientation: p.Keyframes[2].Orientation}.Viewpoint()},
+		} {
+			got := p.Sample(c.time)
+			if got.Position != c.want.Position {
+				t.Errorf("%s path is at %v at %vs, want %v", spline, got.Position, c.time, c.want.Position)
+			}
+			if !near(facing(got.Orientation), facing(c.want.Orientation)) {
+				t.Errorf("%s path faces %v at %vs, want %v", spline, facing(got.Orientation), c.time, facing(c.want.Orientation))
+			}
+		}
+	}
+}
+
+func TestPathValidate(t *testing.T) {
+	saved := bookmarks
+	t.Cleanup(func() { bookmarks = saved })
+	bookmarks = []Bookmark{{Name: "Entrance", Position: mgl32.Vec3{5, 6, 7}, Orientation: identity}}
+
+	keyframe := func(time float64) PathKeyframe {
+		return PathKeyframe{Time: time, Orientation: identity}
+	}
+	for _, c := range []struct {
+		name string
+		path Path
+		// err is part of the error Validate should return, or empty if the path is valid.
+		err string
+	}{
+		{"no keyframes", Path{Spline: CatmullRom}, "at least 2 keyframes"},
+		{"one keyframe", Path{Spline: Bezier, Keyframes: []PathKeyframe{keyframe(0)}}, "at least 2 keyframes"},
+		{"unknown bookmark", Path{Spline: CatmullRom, Keyframes: []PathKeyframe{keyframe(0), {Time: 1, Bookmark: "Exit"}}}, `unknown bookmark "Exit"`},
+		{"unknown spline", Path{Spline: "linear", Keyframes: []PathKeyframe{keyframe(0), keyframe(1)}}, "unknown spline"},
+		{"out of order", Path{Spline: CatmullRom, Keyframes: []PathKeyframe{keyframe(1), keyframe(1)}}, "isn't after the previous keyframe"},
+		{"bookmark", Path{Spline: CatmullRom, Keyframes: []PathKeyframe{keyframe(0), {Time: 1, Bookmark: "Entrance"}}}, ""},
+	} {
+		err := c.path.Validate()
+		switch {
+		case c.err == "" && err != nil:
+			t.Errorf("%s: %v", c.name, err)
+		case c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)):
+			t.Errorf("%s: got error %v, want one containing %q", c.name, err, c.err)
+		}
+	}
+
+	p := Path{Spline: CatmullRom, Keyframes: []PathKeyframe{keyframe(0), {Time: 1, Bookmark: "Entrance"}}}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Sample(1).Position; got != (mgl32.Vec3{5, 6, 7}) {
+		t.Errorf("the keyframe naming a bookmark is at %v, want the bookmark's position %v", got, mgl32.Vec3{5, 6, 7})
+	}
+}
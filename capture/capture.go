@@ -0,0 +1,262 @@
+package capture
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/brandonnelson3/GameEngine/config"
+	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/brandonnelson3/GameEngine/offscreen"
+	"github.com/brandonnelson3/GameEngine/window"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// maxInFlight is how many readbacks can be waiting on the GPU at once. Readbacks are normally finished a frame or two
+// after they are started, so this only fills up when capturing every frame on a slow GPU, in which case the oldest is
+// waited for rather than dropping a frame.
+const maxInFlight = 3
+
+var (
+	// requested are the captures asked for since the last Update, which are started once the frame has been rendered.
+	requested []request
+	inFlight  []*readback
+	buffers   []uint32
+
+	sequenceDir    string
+	sequenceSource Source
+	sequenceFrame  int
+
+	// writing is every PNG still being encoded and written, which happens off the main thread.
+	writing sync.WaitGroup
+)
+
+// Source is a framebuffer or depth texture which can be captured. Every Source is the size of the window.
+type Source struct {
+	framebuffer uint32
+	texture     uint32
+}
+
+// Framebuffer returns a Source which captures the colour of the provided framebuffer, where 0 is the window.
+func Framebuffer(framebuffer uint32) Source {
+	return Source{framebuffer: framebuffer}
+}
+
+// DepthTexture returns a Source which captures the provided depth texture, such as the one shown in the pip. The depth
+// is linearized between the configured near and far planes, so that it is black at the camera and white at the far
+// plane.
+func DepthTexture(texture uint32) Source {
+	return Source{texture: texture}
+}
+
+type request struct {
+	source Source
+	file   string
+}
+
+// readback is a capture which has been started on the GPU.
+type readback struct {
+	request
+	buffer        uint32
+	fence         uintptr
+	width, height int
+}
+
+// Screenshot captures source at the end of the current frame and writes it to the provided PNG file.
+func Screenshot(source Source, file string) {
+	requested = append(requested, request{source, file})
+}
+
+// ScreenshotFile returns a file name for a screenshot, which starts with the provided prefix and is unique to the
+// current time.
+func ScreenshotFile(prefix string) string {
+	return fmt.Sprintf("%s-%s.png", prefix, time.Now().Format("20060102-150405.000"))
+}
+
+// StartSequence captures source at the end of every frame, until StopSequence is called, and writes them to dir as a
+// numbered sequence of PNG files, such as for turning into a video.
+func StartSequence(dir string, source Source) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create frame capture directory %q: %v", dir, err)
+	}
+	sequenceDir, sequenceSource, sequenceFrame = dir, source, 0
+	logf("Capturing every frame to %q", dir)
+	return nil
+}
+
+// StopSequence stops capturing every frame.
+func StopSequence() {
+	if sequenceDir == "" {
+		return
+	}
+	logf("Captured %d frames to %q", sequenceFrame, sequenceDir)
+	sequenceDir = ""
+}
+
+// IsCapturingSequence returns whether every frame is being captured.
+func IsCapturingSequence() bool {
+	return sequenceDir != ""
+}
+
+// Update is intended to be called at the end of every frame, once everything has been rendered but before the buffers
+// are swapped. It starts copying back everything captured this frame, and writes out anything which has finished.
+func Update() {
+	if sequenceDir != "" {
+		sequenceFrame++
+		requested = append(requested, request{sequenceSource, filepath.Join(sequenceDir, fmt.Sprintf("frame_%06d.png", sequenceFrame))})
+	}
+	for _, r := range requested {
+		start(r)
+	}
+	requested = requested[:0]
+	finish(false)
+}
+
+// Flush waits for every capture which has been started to be written out.
+func Flush() {
+	for len(inFlight) > 0 {
+		finish(true)
+	}
+	writing.Wait()
+}
+
+// start asks the GPU to copy r's source into a pixel buffer, which happens in the background without stalling the
+// frame.
+func start(r request) {
+	if len(inFlight) >= maxInFlight {
+		complete(inFlight[0], true)
+		inFlight = inFlight[1:]
+	}
+	var buffer uint32
+	if n := len(buffers); n > 0 {
+		buffer, buffers = buffers[n-1], buffers[:n-1]
+	} else {
+		gl.GenBuffers(1, &buffer)
+	}
+
+	width, height := int(window.GetWidth()), int(window.GetHeight())
+	size := width * height * 4
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, buffer)
+	gl.BufferData(gl.PIXEL_PACK_BUFFER, size, nil, gl.STREAM_READ)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	if r.source.texture != 0 {
+		gl.GetTextureImage(r.source.texture, 0, gl.DEPTH_COMPONENT, gl.FLOAT, int32(size), nil)
+	} else {
+		gl.BindFramebuffer(gl.READ_FRAMEBUFFER, r.source.framebuffer)
+		if r.source.framebuffer == 0 {
+			gl.ReadBuffer(gl.BACK)
+		} else {
+			gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
+		}
+		gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, nil)
+		gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	}
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+
+	inFlight = append(inFlight, &readback{
+		request: r,
+		buffer:  buffer,
+		fence:   gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0),
+		width:   width,
+		height:  height,
+	})
+}
+
+// finish writes out every readback which the GPU has finished, in the order they were started. If wait is true it
+// waits for at least the oldest to finish.
+func finish(wait bool) {
+	for len(inFlight) > 0 {
+		if !complete(inFlight[0], wait) {
+			return
+		}
+		inFlight = inFlight[1:]
+		wait = false
+	}
+}
+
+// complete copies r's pixels out of its buffer and starts writing them to its file, returning false if the GPU hasn't
+// finished with it yet. If wait is true it waits for the GPU instead.
+func complete(r *readback, wait bool) bool {
+	var timeout uint64
+	if wait {
+		timeout = uint64(time.Second)
+	}
+	switch gl.ClientWaitSync(r.fence, gl.SYNC_FLUSH_COMMANDS_BIT, timeout) {
+	case gl.ALREADY_SIGNALED, gl.CONDITION_SATISFIED:
+	case gl.TIMEOUT_EXPIRED:
+		if !wait {
+			return false
+		}
+		logf("Timed out waiting to capture %q", r.file)
+	default:
+		logf("Failed to wait to capture %q", r.file)
+	}
+	gl.DeleteSync(r.fence)
+
+	size := r.width * r.height * 4
+	pixels := make([]byte, size)
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, r.buffer)
+	if p := gl.MapBufferRange(gl.PIXEL_PACK_BUFFER, 0, size, gl.MAP_READ_BIT); p != nil {
+		copy(pixels, unsafe.Slice((*byte)(p), size))
+		gl.UnmapBuffer(gl.PIXEL_PACK_BUFFER)
+	}
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+	buffers = append(buffers, r.buffer)
+
+	projection := config.Get().Projection
+	writing.Add(1)
+	go func() {
+		defer writing.Done()
+		var img image.Image
+		if r.source.texture != 0 {
+			img = depthImage(pixels, r.width, r.height, projection.Near, projection.Far)
+		} else {
+			rgba := &image.RGBA{Pix: pixels, Stride: r.width * 4, Rect: image.Rect(0, 0, r.width, r.height)}
+			offscreen.FlipRows(rgba)
+			img = rgba
+		}
+		if err := writePNG(r.file, img); err != nil {
+			logf("%v", err)
+		}
+	}()
+	return true
+}
+
+// depthImage converts depth values, from bottom to top, into a grey image whose brightness is the linear distance from
+// the near plane to the far plane.
+func depthImage(pixels []byte, width, height int, near, far float32) *image.Gray16 {
+	depths := unsafe.Slice((*float32)(unsafe.Pointer(&pixels[0])), width*height)
+	img := image.NewGray16(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			ndc := 2*depths[(height-1-y)*width+x] - 1
+			linear := 2 * near * far / (far + near - ndc*(far-near))
+			img.SetGray16(x, y, color.Gray16{uint16(65535 * mgl32.Clamp((linear-near)/(far-near), 0, 1))})
+		}
+	}
+	return img
+}
+
+func writePNG(file string, img image.Image) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create capture %q: %v", file, err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write capture %q: %v", file, err)
+	}
+	return f.Close()
+}
+
+func logf(format string, args ...interface{}) {
+	messagebus.SendAsync(&messagebus.Message{System: "Capture", Type: "log", Data1: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,328 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// Window modes, as written in a config file.
+const (
+	Windowed   = "windowed"
+	Fullscreen = "fullscreen"
+	Borderless = "borderless"
+)
+
+var (
+	current = Default()
+	mu      sync.Mutex
+
+	// flags is the FlagSet passed to RegisterFlags, whose flags override every config file which is loaded.
+	flags *flag.FlagSet
+	// flagValues is where the value of each setting's flag, by name, is parsed to.
+	flagValues map[string]interface{}
+)
+
+// Config is every engine setting which is fixed at startup.
+type Config struct {
+	Window     Window     `json:"window"`
+	Projection Projection `json:"projection"`
+	Lighting   Lighting   `json:"lighting"`
+	Framerate  Framerate  `json:"framerate"`
+	Mouse      Mouse      `json:"mouse"`
+}
+
+// Window is how the window is created.
+type Window struct {
+	// Width and Height are the size the window is created at, in pixels, which can be resized afterwards.
+	Width  uint32 `json:"width"`
+	Height uint32 `json:"height"`
+	Title  string `json:"title"`
+	// Mode is one of Windowed, Fullscreen or Borderless.
+	Mode string `json:"mode"`
+	// Samples is the number of samples per pixel used for multisample anti-aliasing, where 0 turns it off.
+	Samples int `json:"samples"`
+	// Monitor is the index of the monitor to open the window on, in the order they are listed by the window package,
+	// where 0 is the primary monitor.
+	Monitor int `json:"monitor"`
+	// FullscreenWidth and FullscreenHeight are the resolution of the video mode used in Fullscreen mode, where 0 uses
+	// Width and Height.
+	FullscreenWidth  uint32 `json:"fullscreenWidth"`
+	FullscreenHeight uint32 `json:"fullscreenHeight"`
+	// RefreshRate is the refresh rate, in Hz, of the video mode used in Fullscreen mode, where 0 picks the highest one
+	// available at its resolution.
+	RefreshRate int `json:"refreshRate"`
+	// ScaleToContent is whether Width and Height are scaled up by the monitor's content scale in Windowed mode, so that
+	// the window is the same size on a high DPI monitor as on any other.
+	ScaleToContent bool `json:"scaleToContent"`
+	// Headless is whether the window is hidden, with everything rendered into an offscreen framebuffer of Width by Height
+	// instead, so that the engine can run without a display, such as on a CI server using a software rasterizer.
+	Headless bool `json:"headless"`
+}
+
+// FullscreenSize returns the resolution of the video mode used in Fullscreen mode.
+func (w Window) FullscreenSize() (uint32, uint32) {
+	if w.FullscreenWidth == 0 && w.FullscreenHeight == 0 {
+		return w.Width, w.Height
+	}
+	return w.FullscreenWidth, w.FullscreenHeight
+}
+
+// Projection is the camera's perspective projection.
+type Projection struct {
+	// Fov is the vertical field of view, in degrees, the camera starts with.
+	Fov  float32 `json:"fov"`
+	Near float32 `json:"near"`
+	Far  float32 `json:"far"`
+}
+
+// Lighting is how point lights are culled.
+type Lighting struct {
+	// TileSize is the width and height, in pixels, of the screen tiles lights are culled against.
+	TileSize uint32 `json:"tileSize"`
+}
+
+// Framerate is how fast frames are rendered.
+type Framerate struct {
+	// Cap is the most frames rendered each second, where 0 renders them as fast as possible.
+	Cap float64 `json:"cap"`
+}
+
+// Mouse is how mouse motion is turned into input.MouseDeltas while the cursor is captured.
+type Mouse struct {
+	// Sensitivity multiplies mouse motion, in pixels.
+	Sensitivity float64 `json:"sensitivity"`
+	// Smoothing is how much of the previous frame's motion is blended into the current frame's, from 0 for none.
+	Smoothing float64 `json:"smoothing"`
+	// Raw is whether motion is read straight from the mouse, without the platform's pointer acceleration, where the
+	// platform supports it.
+	Raw bool `json:"raw"`
+}
+
+// Default returns the settings used when there is no config file.
+func Default() Config {
+	return Config{
+		Window: Window{
+			Width:          1920,
+			Height:         1080,
+			Title:          "Game Engine Demo",
+			Mode:           Windowed,
+			Samples:        4,
+			ScaleToContent: true,
+		},
+		Projection: Projection{
+			Fov:  45,
+			Near: 0.1,
+			Far:  1000,
+		},
+		Lighting: Lighting{
+			TileSize: 16,
+		},
+		Framerate: Framerate{
+			Cap: 105,
+		},
+		Mouse: Mouse{
+			Sensitivity: 1,
+			Raw:         true,
+		},
+	}
+}
+
+// Get returns the current settings.
+func Get() Config {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// Set replaces the current settings, after checking they are valid. Nothing is changed if they aren't.
+func Set(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	current = c
+	return nil
+}
+
+// Load reads settings from the provided JSON config file, if it is not empty, over the top of the defaults, then
+// applies any flags registered with RegisterFlags which were set on the command line, and makes the result current.
+func Load(file string) error {
+	c := Default()
+	if file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read config %q: %v", file, err)
+		}
+		d := json.NewDecoder(bytes.NewReader(data))
+		// A misspelt setting would otherwise be silently ignored.
+		d.DisallowUnknownFields()
+		if err := d.Decode(&c); err != nil {
+			return fmt.Errorf("failed to parse config %q: %v", file, err)
+		}
+	}
+	if err := c.applyFlags(); err != nil {
+		return err
+	}
+	if err := Set(c); err != nil {
+		if file == "" {
+			return fmt.Errorf("invalid config: %v", err)
+		}
+		return fmt.Errorf("invalid config %q: %v", file, err)
+	}
+	return nil
+}
+
+// Save writes the current settings to the provided file, in the format read by Load.
+func Save(file string) error {
+	data, err := json.MarshalIndent(Get(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+// Validate returns an error describing the first setting which is out of range.
+func (c *Config) Validate() error {
+	switch {
+	case c.Window.Width == 0 || c.Window.Height == 0:
+		return fmt.Errorf("window size %dx%d must not be 0 in either dimension", c.Window.Width, c.Window.Height)
+	case c.Window.Mode != Windowed && c.Window.Mode != Fullscreen && c.Window.Mode != Borderless:
+		return fmt.Errorf("window mode %q must be %q, %q or %q", c.Window.Mode, Windowed, Fullscreen, Borderless)
+	case c.Window.Samples < 0 || c.Window.Samples > 32:
+		return fmt.Errorf("window samples %d must be in the range [0, 32]", c.Window.Samples)
+	case c.Window.Monitor < 0:
+		return fmt.Errorf("window monitor %d must not be negative", c.Window.Monitor)
+	case (c.Window.FullscreenWidth == 0) != (c.Window.FullscreenHeight == 0):
+		return fmt.Errorf("window fullscreen size %dx%d must be 0 in both dimensions or neither", c.Window.FullscreenWidth, c.Window.FullscreenHeight)
+	case c.Window.RefreshRate < 0:
+		return fmt.Errorf("window refresh rate %d must not be negative", c.Window.RefreshRate)
+	case c.Projection.Fov <= 0 || c.Projection.Fov >= 180:
+		return fmt.Errorf("projection fov %v must be in the range (0, 180)", c.Projection.Fov)
+	case c.Projection.Near <= 0:
+		return fmt.Errorf("projection near %v must be greater than 0", c.Projection.Near)
+	case c.Projection.Far <= c.Projection.Near:
+		return fmt.Errorf("projection far %v must be greater than near %v", c.Projection.Far, c.Projection.Near)
+	// A tile is culled by one compute work group, which OpenGL only guarantees can be 1024 invocations. Every tile also
+	// has room for the index of every point light, so smaller tiles make that buffer gigabytes in size.
+	case c.Lighting.TileSize < 8 || c.Lighting.TileSize > 32:
+		return fmt.Errorf("lighting tile size %d must be in the range [8, 32]", c.Lighting.TileSize)
+	case c.Framerate.Cap < 0:
+		return fmt.Errorf("framerate cap %v must not be negative", c.Framerate.Cap)
+	case c.Mouse.Sensitivity <= 0:
+		return fmt.Errorf("mouse sensitivity %v must be greater than 0", c.Mouse.Sensitivity)
+	// Smoothing any closer to 1 would keep the camera turning long after the mouse stopped.
+	case c.Mouse.Smoothing < 0 || c.Mouse.Smoothing > 0.99:
+		return fmt.Errorf("mouse smoothing %v must be in the range [0, 0.99]", c.Mouse.Smoothing)
+	}
+	return nil
+}
+
+// setting is a single setting which can be overridden from the command line.
+type setting struct {
+	name  string
+	usage string
+	// field returns a pointer to the setting within c.
+	field func(c *Config) interface{}
+}
+
+var settings = []setting{
+	{"window.width", "Width of the window in pixels.", func(c *Config) interface{} { return &c.Window.Width }},
+	{"window.height", "Height of the window in pixels.", func(c *Config) interface{} { return &c.Window.Height }},
+	{"window.title", "Title of the window.", func(c *Config) interface{} { return &c.Window.Title }},
+	{"window.mode", "How the window is shown: windowed, fullscreen or borderless.", func(c *Config) interface{} { return &c.Window.Mode }},
+	{"window.samples", "Samples per pixel for multisample anti-aliasing, or 0 for none.", func(c *Config) interface{} { return &c.Window.Samples }},
+	{"window.monitor", "Index of the monitor to open the window on, where 0 is the primary monitor.", func(c *Config) interface{} { return &c.Window.Monitor }},
+	{"window.fullscreenwidth", "Width of the video mode used in fullscreen mode, or 0 for the width of the window.", func(c *Config) interface{} { return &c.Window.FullscreenWidth }},
+	{"window.fullscreenheight", "Height of the video mode used in fullscreen mode, or 0 for the height of the window.", func(c *Config) interface{} { return &c.Window.FullscreenHeight }},
+	{"window.refreshrate", "Refresh rate in Hz used in fullscreen mode, or 0 for the highest available.", func(c *Config) interface{} { return &c.Window.RefreshRate }},
+	{"window.scaletocontent", "Scales the size of the window up on high DPI monitors.", func(c *Config) interface{} { return &c.Window.ScaleToContent }},
+	{"window.headless", "Hides the window and renders offscreen instead, such as with LIBGL_ALWAYS_SOFTWARE=1 under Xvfb.", func(c *Config) interface{} { return &c.Window.Headless }},
+	{"projection.fov", "Vertical field of view in degrees.", func(c *Config) interface{} { return &c.Projection.Fov }},
+	{"projection.near", "Distance to the near plane.", func(c *Config) interface{} { return &c.Projection.Near }},
+	{"projection.far", "Distance to the far plane.", func(c *Config) interface{} { return &c.Projection.Far }},
+	{"lighting.tilesize", "Size in pixels of the screen tiles point lights are culled against.", func(c *Config) interface{} { return &c.Lighting.TileSize }},
+	{"framerate.cap", "Most frames rendered per second, or 0 for no limit.", func(c *Config) interface{} { return &c.Framerate.Cap }},
+	{"mouse.sensitivity", "Multiplier applied to mouse motion.", func(c *Config) interface{} { return &c.Mouse.Sensitivity }},
+	{"mouse.smoothing", "How much of the previous frame's mouse motion is blended into the current frame's, from 0 to 0.99.", func(c *Config) interface{} { return &c.Mouse.Smoothing }},
+	{"mouse.raw", "Reads mouse motion without pointer acceleration, where supported.", func(c *Config) interface{} { return &c.Mouse.Raw }},
+}
+
+// RegisterFlags adds a flag to fs for every setting, such as -window.width, which overrides the config file when it is
+// set. Each flag has its setting's type, so a bool setting such as -window.headless can be set without a value. It must
+// be called before fs is parsed.
+func RegisterFlags(fs *flag.FlagSet) {
+	d := Default()
+	flagValues = make(map[string]interface{})
+	for _, s := range settings {
+		switch v := s.field(&d).(type) {
+		case *string:
+			flagValues[s.name] = fs.String(s.name, *v, s.usage)
+		case *bool:
+			flagValues[s.name] = fs.Bool(s.name, *v, s.usage)
+		case *int:
+			flagValues[s.name] = fs.Int(s.name, *v, s.usage)
+		case *uint32:
+			flagValues[s.name] = fs.Uint(s.name, uint(*v), s.usage)
+		case *float32:
+			// Formatted as a float32, so that the default is shown as 0.1 rather than 0.10000000149011612.
+			f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(*v), 'g', -1, 32), 64)
+			flagValues[s.name] = fs.Float64(s.name, f, s.usage)
+		case *float64:
+			flagValues[s.name] = fs.Float64(s.name, *v, s.usage)
+		default:
+			panic(fmt.Sprintf("unsupported setting type %T", v))
+		}
+	}
+	flags = fs
+}
+
+// applyFlags sets every setting whose flag was set on the command line.
+func (c *Config) applyFlags() error {
+	if flags == nil {
+		return nil
+	}
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.name != f.Name || err != nil {
+				continue
+			}
+			if e := set(s.field(c), flagValues[s.name]); e != nil {
+				err = fmt.Errorf("invalid value %q for flag -%s: %v", f.Value.String(), f.Name, e)
+			}
+		}
+	})
+	return err
+}
+
+// set copies the value of a setting's flag into the setting which field points to.
+func set(field, value interface{}) error {
+	switch v := field.(type) {
+	case *string:
+		*v = *value.(*string)
+	case *bool:
+		*v = *value.(*bool)
+	case *int:
+		*v = *value.(*int)
+	case *uint32:
+		u := *value.(*uint)
+		if u > math.MaxUint32 {
+			return fmt.Errorf("%d is out of range", u)
+		}
+		*v = uint32(u)
+	case *float32:
+		*v = float32(*value.(*float64))
+	case *float64:
+		*v = *value.(*float64)
+	default:
+		panic(fmt.Sprintf("unsupported setting type %T", field))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+// parseFlags registers every setting's flag on a new FlagSet, and parses args with it, until the test is done.
+func parseFlags(t *testing.T, args ...string) *flag.FlagSet {
+	t.Cleanup(func() {
+		flags, flagValues = nil, nil
+		if err := Set(Default()); err != nil {
+			t.Fatal(err)
+		}
+	})
+	fs := flag.NewFlagSet("engine", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func TestFlagsOverrideSettings(t *testing.T) {
+	parseFlags(t, "-window.headless", "-window.width", "800", "-window.mode=borderless", "-projection.fov", "60.5", "-framerate.cap", "30")
+	if err := Load(""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Default()
+	want.Window.Headless = true
+	want.Window.Width = 800
+	want.Window.Mode = Borderless
+	want.Projection.Fov = 60.5
+	want.Framerate.Cap = 30
+	if got := Get(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestFlagsShowTheirTypes(t *testing.T) {
+	fs := parseFlags(t)
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+
+	for _, want := range []string{
+		"-window.headless\n",
+		"-window.width uint\n",
+		"-window.title string\n",
+		"-window.samples int\n",
+		"-projection.near float\n",
+		"(default 0.1)",
+	} {
+		if !strings.Contains(usage.String(), want) {
+			t.Errorf("usage doesn't contain %q:\n%s", want, usage.String())
+		}
+	}
+}
+
+func TestInvalidFlagValuesAreRejected(t *testing.T) {
+	fs := flag.NewFlagSet("engine", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	RegisterFlags(fs)
+	t.Cleanup(func() { flags, flagValues = nil, nil })
+
+	if err := fs.Parse([]string{"-window.width", "wide"}); err == nil {
+		t.Errorf("-window.width wide was accepted")
+	}
+}
+
+func TestValidateTileSize(t *testing.T) {
+	for size, valid := range map[uint32]bool{0: false, 1: false, 7: false, 8: true, 16: true, 32: true, 33: false} {
+		c := Default()
+		c.Lighting.TileSize = size
+		if err := c.Validate(); (err == nil) != valid {
+			t.Errorf("tile size %d returned %v", size, err)
+		}
+	}
+}
+
+func TestFullscreenSize(t *testing.T) {
+	c := Default()
+	if w, h := c.Window.FullscreenSize(); w != c.Window.Width || h != c.Window.Height {
+		t.Errorf("unset fullscreen size is %dx%d, want the window size %dx%d", w, h, c.Window.Width, c.Window.Height)
+	}
+
+	c.Window.FullscreenWidth, c.Window.FullscreenHeight = 2560, 1440
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := c.Window.FullscreenSize(); w != 2560 || h != 1440 {
+		t.Errorf("fullscreen size is %dx%d, want 2560x1440", w, h)
+	}
+
+	c.Window.FullscreenHeight = 0
+	if err := c.Validate(); err == nil {
+		t.Errorf("a fullscreen size of 2560x0 was accepted")
+	}
+}
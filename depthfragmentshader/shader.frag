@@ -0,0 +1,4 @@
+#version 450
+void main() {
+	// We are not drawing anything to the screen, so nothing to be done here
+}
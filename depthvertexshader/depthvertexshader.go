@@ -1,37 +1,12 @@
 package depthvertexshader
 
 import (
-	"fmt"
-	"strings"
-
+	"github.com/brandonnelson3/GameEngine/shader"
 	"github.com/brandonnelson3/GameEngine/uniforms"
 	"github.com/go-gl/gl/v4.5-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
-const (
-	originalVertexSourceFile = `shader.vert`
-	vertSrc                  = `
-#version 450
-
-uniform mat4 projection;
-uniform mat4 view;
-uniform mat4 model;
-
-in vec3 vert;
-in vec3 norm;
-in vec2 uv;
-
-out gl_PerVertex
-{
-    vec4 gl_Position;
-};
-
-void main() {
-    gl_Position = projection * view * model * vec4(vert, 1);
-}` + "\x00"
-)
-
 // DepthVertex is a Vertex.
 type DepthVertex struct {
 	Vert mgl32.Vec3
@@ -46,53 +21,16 @@ type DepthVertexShader struct {
 
 // NewDepthVertexShader instantiates and initializes a shader object.
 func NewDepthVertexShader() (*DepthVertexShader, error) {
-	program := gl.CreateProgram()
-	shader := gl.CreateShader(gl.VERTEX_SHADER)
-
-	csources, free := gl.Strs(vertSrc)
-	gl.ShaderSource(shader, 1, csources, nil)
-	free()
-	gl.CompileShader(shader)
-
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
-
-		return nil, fmt.Errorf("failed to compile %v: %v", originalVertexSourceFile, log)
+	p, err := shader.Load("depthvertexshader/shader.vert")
+	if err != nil {
+		return nil, err
 	}
-
-	gl.AttachShader(program, shader)
-	gl.ProgramParameteri(program, gl.PROGRAM_SEPARABLE, gl.TRUE)
-	gl.LinkProgram(program)
-
-	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
-
-		return nil, fmt.Errorf("failed to link %v: %v", originalVertexSourceFile, log)
+	s := &DepthVertexShader{uint32: p.ID()}
+	if err := p.Populate(s); err != nil {
+		return nil, err
 	}
-
-	projectionLoc := gl.GetUniformLocation(program, gl.Str("projection\x00"))
-	viewLoc := gl.GetUniformLocation(program, gl.Str("view\x00"))
-	modelLoc := gl.GetUniformLocation(program, gl.Str("model\x00"))
-
-	gl.DeleteShader(shader)
-
-	return &DepthVertexShader{
-		uint32:     program,
-		Projection: uniforms.NewMatrix4(program, projectionLoc),
-		View:       uniforms.NewMatrix4(program, viewLoc),
-		Model:      uniforms.NewMatrix4(program, modelLoc),
-	}, nil
+	p.OnReload(func() error { return p.Populate(s) })
+	return s, nil
 }
 
 // AddToPipeline adds this shader to the provided pipeline.
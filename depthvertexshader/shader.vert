@@ -0,0 +1,18 @@
+#version 450
+
+uniform mat4 projection;
+uniform mat4 view;
+uniform mat4 model;
+
+in vec3 vert;
+in vec3 norm;
+in vec2 uv;
+
+out gl_PerVertex
+{
+    vec4 gl_Position;
+};
+
+void main() {
+    gl_Position = projection * view * model * vec4(vert, 1);
+}
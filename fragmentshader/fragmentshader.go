@@ -2,188 +2,154 @@ package fragmentshader
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/brandonnelson3/GameEngine/buffers"
+	"github.com/brandonnelson3/GameEngine/input"
 	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/brandonnelson3/GameEngine/shader"
 	"github.com/brandonnelson3/GameEngine/uniforms"
 	"github.com/go-gl/gl/v4.5-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
 )
 
-const (
-	originalFragmentSourceFile = `shader.frag`
-	fragSrc                    = `
-#version 450
-
-// TODO: Probably can pull this out into a common place.
-struct PointLight {
-	vec3 color;
-	float intensity;
-	vec3 position;
-	float radius;
-};
-
-struct VisibleIndex {
-	int index;
-};
-
-struct DirectionalLight {
-	vec3 color;
-	float brightness;
-	vec3 direction;
-};
-
-// Shader storage buffer objects
-layout(std430, binding = 0) readonly buffer LightBuffer {
-	PointLight data[];
-} lightBuffer;
-
-layout(std430, binding = 1) readonly buffer VisibleLightIndicesBuffer {
-	VisibleIndex data[];
-} visibleLightIndicesBuffer;
-
-layout(std430, binding = 2) readonly buffer DirectionalLightBuffer {
-	DirectionalLight data;
-} directionalLightBuffer;
-
-uniform int renderMode;
-uniform uint numTilesX;
-uniform sampler2D diffuse;
-
-in VERTEX_OUT
-{
-    vec4 gl_Position;
-	vec3 worldPosition;
-	vec3 normal;
-	vec2 uv;
-} fragment_in;
-
-out vec4 outputColor;
-
-void main() {
-	ivec2 location = ivec2(gl_FragCoord.xy);
-	// TODO: Put this 16 somewhere constant.
-	ivec2 tileID = location / ivec2(16, 16);
-	uint index = tileID.y * numTilesX + tileID.x;
-
-	// TODO 1024 should be somewhere constant.
-	uint offset = index * 1024;
-	
-	if (renderMode == 0) {
-		vec3 pointLightColor = vec3(0, 0, 0);
-
-		uint i=0;
-		for (i; i < 1024 && visibleLightIndicesBuffer.data[offset + i].index != -1; i++) {
-			uint lightIndex = visibleLightIndicesBuffer.data[offset + i].index;
-			PointLight light = lightBuffer.data[lightIndex];
-			vec3 lightVector = light.position - fragment_in.worldPosition;
-			float dist = length(lightVector);
-			float NdL = max(0.0f, dot(fragment_in.normal, lightVector*(1.0f/dist)));
-			float attenuation = 1.0f - clamp(dist * (1.0/(light.radius)), 0.0, 1.0);
-			vec3 diffuse = NdL * light.color * light.intensity;
-			pointLightColor += attenuation * diffuse;
-		}
-
-		DirectionalLight directionalLight = directionalLightBuffer.data;
-		float NdL = max(0.0f, dot(fragment_in.normal, -1*directionalLight.direction));
-		vec3 directionalLightColor = NdL * directionalLight.color * directionalLight.brightness;
-
-		outputColor = texture(diffuse, fragment_in.uv) * vec4(pointLightColor+directionalLightColor, 1.0);
-	} else if (renderMode == 1) {
-		uint i=0;
-		for (i; i < 1024 && visibleLightIndicesBuffer.data[offset + i].index != -1; i++) {}
-		outputColor = vec4(vec3(float(i)/256)+vec3(0.1), 1.0);
-	} else if (renderMode == 2) {
-		outputColor = vec4(abs(fragment_in.normal), 1.0);
-	} else if (renderMode == 3) {
-		outputColor = vec4(fragment_in.uv, 0, 1.0);
-	} else if (renderMode == 4) {
-		outputColor = texture(diffuse, fragment_in.uv);
-	}	
+// renderModes are the features of the shader variant for each render mode, where 0 is the lit scene and the rest are
+// the debug views.
+var renderModes = [][]string{
+	nil,
+	{"DEBUG_TILE_HEATMAP"},
+	{"DEBUG_NORMALS"},
+	{"DEBUG_UVS"},
+	{"UNLIT"},
 }
-` + "\x00"
-)
 
-// FragmentShader represents a FragmentShader
+// FragmentShader represents a FragmentShader, which is whichever variant of it the current render mode and material use.
+// Its uniforms and buffers are those of the current variant, and the ones which the variant doesn't use do nothing.
 type FragmentShader struct {
 	uint32
 
-	RenderMode *uniforms.Int
-	NumTilesX  *uniforms.UInt
-	Diffuse    *uniforms.Sampler2D
+	NumTilesX *uniforms.UInt      `uniform:",optional"`
+	Diffuse   *uniforms.Sampler2D `uniform:",optional"`
 
-	LightBuffer, VisibleLightIndicesBuffer, DirectionalLightBuffer *buffers.Binding
+	LightBuffer, VisibleLightIndicesBuffer, DirectionalLightBuffer *buffers.Binding `buffer:",optional"`
+
+	variants *shader.Variants
+	program  *shader.Program
+	// built are the variants which have been set up, and are populated again whenever they are reloaded.
+	built map[*shader.Program]bool
+	// mode is the current render mode, and features are the ones the material being drawn asked for.
+	mode     int32
+	features []string
+	// pipelines are every pipeline this shader has been added to, which are switched over to each new variant.
+	pipelines []uint32
 }
 
-// NewFragmentShader instantiates and initializes a FragmentShader object.
+// NewFragmentShader instantiates and initializes a FragmentShader object. Every render mode's variant is built up
+// front, so that switching between them doesn't stall.
 func NewFragmentShader() (*FragmentShader, error) {
-	program := gl.CreateProgram()
-	shader := gl.CreateShader(gl.FRAGMENT_SHADER)
-
-	csources, free := gl.Strs(fragSrc)
-	gl.ShaderSource(shader, 1, csources, nil)
-	free()
-	gl.CompileShader(shader)
-
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
-
-		return nil, fmt.Errorf("failed to compile %v: %v", originalFragmentSourceFile, log)
+	fs := &FragmentShader{
+		variants: shader.NewVariants("fragmentshader/shader.frag"),
+		built:    make(map[*shader.Program]bool),
+	}
+	for _, features := range renderModes {
+		if _, err := fs.variant(features); err != nil {
+			return nil, err
+		}
+	}
+	if err := fs.SetRenderMode(0); err != nil {
+		return nil, err
 	}
 
-	gl.AttachShader(program, shader)
-	gl.ProgramParameteri(program, gl.PROGRAM_SEPARABLE, gl.TRUE)
-	gl.LinkProgram(program)
-
-	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+	input.SubscribeActions(messagebus.PriorityDebug, func(a input.ActionInput) {
+		for i := range renderModes {
+			action := fmt.Sprintf("RenderMode%d", i)
+			if a.WasPressed(action) {
+				if err := fs.SetRenderMode(int32(i)); err != nil {
+					messagebus.SendAsync(&messagebus.Message{System: "FragmentShader", Type: "log", Data1: err.Error()})
+				}
+			}
+			a.Consume(action)
+		}
+	})
 
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+	return fs, nil
+}
 
-		return nil, fmt.Errorf("failed to link %v: %v", originalFragmentSourceFile, log)
+// variant returns the variant with the provided features, setting it up the first time it is asked for.
+func (s *FragmentShader) variant(features []string) (*shader.Program, error) {
+	p, err := s.variants.Get(features...)
+	if err != nil {
+		return nil, err
+	}
+	if s.built[p] {
+		return p, nil
 	}
 
-	renderModeLoc := gl.GetUniformLocation(program, gl.Str("renderMode\x00"))
-	numTilesXLoc := gl.GetUniformLocation(program, gl.Str("numTilesX\x00"))
-	diffuseLoc := gl.GetUniformLocation(program, gl.Str("diffuse\x00"))
-
-	gl.DeleteShader(shader)
-
-	gl.BindFragDataLocation(program, 0, gl.Str("outputColor\x00"))
+	gl.BindFragDataLocation(p.ID(), 0, gl.Str("outputColor\x00"))
 
-	fs := &FragmentShader{
-		uint32:                    program,
-		RenderMode:                uniforms.NewInt(program, renderModeLoc),
-		NumTilesX:                 uniforms.NewUInt(program, numTilesXLoc),
-		Diffuse:                   uniforms.NewSampler2D(program, diffuseLoc),
-		LightBuffer:               buffers.NewBinding(0),
-		VisibleLightIndicesBuffer: buffers.NewBinding(1),
-		DirectionalLightBuffer:    buffers.NewBinding(2),
+	if err := p.Populate(&FragmentShader{}); err != nil {
+		return nil, err
 	}
-
-	messagebus.RegisterType("key", func(m *messagebus.Message) {
-		pressedKeys := m.Data1.([]glfw.Key)
-		for _, key := range pressedKeys {
-			if key >= glfw.KeyF1 && key <= glfw.KeyF25 {
-				fs.RenderMode.Set(int32(key - glfw.KeyF1))
-			}
+	s.built[p] = true
+	// shader.frag is shared by every variant, so a change to it relinks them all. Each is populated again as it is,
+	// the ones which aren't in use into a scratch FragmentShader, so that one which no longer matches is reported now
+	// rather than when it is switched to.
+	p.OnReload(func() error {
+		if p != s.program {
+			return p.Populate(&FragmentShader{})
 		}
+		return p.Populate(s)
 	})
+	return p, nil
+}
 
-	return fs, nil
+// SetRenderMode switches to the variant for the provided render mode, which is one of the debug views, or 0 for the lit
+// scene.
+func (s *FragmentShader) SetRenderMode(mode int32) error {
+	if mode < 0 || int(mode) >= len(renderModes) {
+		return fmt.Errorf("render mode %d must be in the range [0, %d)", mode, len(renderModes))
+	}
+	if err := s.use(mode, s.features); err != nil {
+		return err
+	}
+	s.mode = mode
+	return nil
+}
+
+// UseFeatures switches to the variant with the provided features defined, such as those a material needs, which is
+// built the first time they are asked for. The debug views replace the material's shading, so while one is shown the
+// features are only remembered until the render mode goes back to 0. The uniforms need setting again after the variant
+// changes.
+func (s *FragmentShader) UseFeatures(features ...string) error {
+	if err := s.use(s.mode, features); err != nil {
+		return err
+	}
+	s.features = append(s.features[:0], features...)
+	return nil
+}
+
+// use switches to the variant for the provided render mode and material features, unless it is already in use.
+func (s *FragmentShader) use(mode int32, features []string) error {
+	if mode != 0 {
+		features = renderModes[mode]
+	}
+	p, err := s.variant(features)
+	if err != nil {
+		return err
+	}
+	if p == s.program {
+		return nil
+	}
+	if err := p.Populate(s); err != nil {
+		return err
+	}
+	s.program, s.uint32 = p, p.ID()
+	for _, pipeline := range s.pipelines {
+		gl.UseProgramStages(pipeline, gl.FRAGMENT_SHADER_BIT, s.uint32)
+	}
+	return nil
 }
 
 // AddToPipeline adds this shader to the provided pipeline.
 func (s *FragmentShader) AddToPipeline(pipeline uint32) {
+	s.pipelines = append(s.pipelines, pipeline)
 	gl.UseProgramStages(pipeline, gl.FRAGMENT_SHADER_BIT, s.uint32)
 }
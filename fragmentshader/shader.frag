@@ -0,0 +1,88 @@
+#version 450
+
+#include "lights/lights.glsl"
+
+// Shader storage buffer objects
+layout(std430, binding = 0) readonly buffer LightBuffer {
+	PointLight data[];
+} lightBuffer;
+
+layout(std430, binding = 1) readonly buffer VisibleLightIndicesBuffer {
+	VisibleIndex data[];
+} visibleLightIndicesBuffer;
+
+layout(std430, binding = 2) readonly buffer DirectionalLightBuffer {
+	DirectionalLight data;
+} directionalLightBuffer;
+
+uniform uint numTilesX;
+uniform sampler2D diffuse;
+
+in VERTEX_OUT
+{
+	vec3 worldPosition;
+	vec3 normal;
+	vec2 uv;
+} fragment_in;
+
+out vec4 outputColor;
+
+// Without any features this is the lit scene, which a material can add to with:
+// ANTI_TILING, to hide how often the diffuse texture repeats over a large surface.
+// The debug views replace the lit scene, and are:
+// DEBUG_TILE_HEATMAP, the number of lights in each tile, as a colour ramp;
+// DEBUG_NORMALS, the normals;
+// DEBUG_UVS, the texture coordinates;
+// UNLIT, the diffuse texture without any lighting.
+void main() {
+#if defined(DEBUG_NORMALS)
+	outputColor = vec4(abs(fragment_in.normal), 1.0);
+#elif defined(DEBUG_UVS)
+	outputColor = vec4(fragment_in.uv, 0, 1.0);
+#elif defined(UNLIT)
+	outputColor = texture(diffuse, fragment_in.uv);
+#else
+	ivec2 location = ivec2(gl_FragCoord.xy);
+	ivec2 tileID = location / ivec2(TILE_SIZE, TILE_SIZE);
+	uint index = tileID.y * numTilesX + tileID.x;
+
+	uint offset = index * MAXIMUM_POINT_LIGHTS;
+
+#if defined(DEBUG_TILE_HEATMAP)
+	uint i=0;
+	for (i; i < MAXIMUM_POINT_LIGHTS && visibleLightIndicesBuffer.data[offset + i].index != -1; i++) {}
+	// Each light moves the colour a long way along the ramp, from dark blue for none through cyan, green and yellow to red
+	// for heatmapFull or more, so that one more or less in a tile is plain to see.
+	const float heatmapFull = 8.0;
+	float heat = 4.0 * min(float(i) / heatmapFull, 1.0);
+	outputColor = vec4(clamp(vec3(1.5) - abs(vec3(heat) - vec3(3, 2, 1)), 0.0, 1.0), 1.0);
+#else
+	vec3 pointLightColor = vec3(0, 0, 0);
+
+	uint i=0;
+	for (i; i < MAXIMUM_POINT_LIGHTS && visibleLightIndicesBuffer.data[offset + i].index != -1; i++) {
+		uint lightIndex = visibleLightIndicesBuffer.data[offset + i].index;
+		PointLight light = lightBuffer.data[lightIndex];
+		vec3 lightVector = light.position - fragment_in.worldPosition;
+		float dist = length(lightVector);
+		float NdL = max(0.0f, dot(fragment_in.normal, lightVector*(1.0f/dist)));
+		float attenuation = 1.0f - clamp(dist * (1.0/(light.radius)), 0.0, 1.0);
+		vec3 diffuse = NdL * light.color * light.intensity;
+		pointLightColor += attenuation * diffuse;
+	}
+
+	DirectionalLight directionalLight = directionalLightBuffer.data;
+	float NdL = max(0.0f, dot(fragment_in.normal, -1*directionalLight.direction));
+	vec3 directionalLightColor = NdL * directionalLight.color * directionalLight.brightness;
+
+	vec4 albedo = texture(diffuse, fragment_in.uv);
+#if defined(ANTI_TILING)
+	// The texture is blended with a copy of itself, turned and scaled so that the two never repeat in step, which breaks
+	// up the grid of repeats that shows from a distance.
+	albedo = mix(albedo, texture(diffuse, mat2(0.8, 0.6, -0.6, 0.8) * fragment_in.uv * 0.37), 0.5);
+#endif
+
+	outputColor = albedo * vec4(pointLightColor+directionalLightColor, 1.0);
+#endif
+#endif
+}
@@ -5,12 +5,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/brandonnelson3/GameEngine/config"
 	"github.com/brandonnelson3/GameEngine/messagebus"
 )
 
 const (
 	numAveragedFrameLengths = 25
-	framerateCap            = 105
 )
 
 var (
@@ -28,12 +28,19 @@ func init() {
 
 func log() {
 	for range time.Tick(time.Millisecond * 500) {
-		if frames < numAveragedFrameLengths {
+		mu.Lock()
+		averaged := frames >= numAveragedFrameLengths
+		mu.Unlock()
+		if !averaged {
 			continue
 		}
 
 		averageFrameTime, averageFramesPerSecond := calculateFrameDetails()
-		messagebus.SendSync(&messagebus.Message{System: "FrameRate", Type: "log", Data1: fmt.Sprintf("Length: %.3f ms - Avg FPS: %.1f - Limiting framerate to %d", averageFrameTime*1000, averageFramesPerSecond, framerateCap)})
+		limit := "Not limiting framerate"
+		if framerateCap := config.Get().Framerate.Cap; framerateCap > 0 {
+			limit = fmt.Sprintf("Limiting framerate to %v", framerateCap)
+		}
+		messagebus.SendAsync(&messagebus.Message{System: "FrameRate", Type: "log", Data1: fmt.Sprintf("Length: %.3f ms - Avg FPS: %.1f - %s", averageFrameTime*1000, averageFramesPerSecond, limit)})
 	}
 }
 
@@ -66,6 +73,10 @@ func EndOfFrame(now float64) {
 		i = 0
 	}
 	mu.Unlock()
+	framerateCap := config.Get().Framerate.Cap
+	if framerateCap <= 0 {
+		return
+	}
 	// Sleep for as long as we need to...
 	time.Sleep(time.Duration((float64(time.Second) / framerateCap) - (float64(time.Second) * d)))
 }
@@ -0,0 +1,227 @@
+package golden
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/brandonnelson3/GameEngine/camera"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// Width and Height are the size every scene is rendered at, which the reference images must match.
+	Width  = 640
+	Height = 360
+
+	// maxDelta is the largest possible value of delta, between cyan and red.
+	maxDelta = 35215
+)
+
+// Scene is a canned view of the engine's demo scene, which is the grid of cubes lit by the four coloured point lights.
+type Scene struct {
+	// Name is the name of the scene's reference image, without the .png.
+	Name       string
+	RenderMode int32
+	Viewpoint  camera.Viewpoint
+}
+
+// Scenes returns every canned scene, which is the whole grid in each of the fragment shader's render modes, looked at
+// from one fixed camera.
+func Scenes() []Scene {
+	v := camera.Viewpoint{
+		Position:    mgl32.Vec3{-22.585495, 22.307711, -21.923943},
+		Orientation: camera.OrientationFromAngles(5.506999, -0.476000),
+	}
+	var scenes []Scene
+	for mode := int32(0); mode < 5; mode++ {
+		scenes = append(scenes, Scene{Name: fmt.Sprintf("rendermode%d", mode), RenderMode: mode, Viewpoint: v})
+	}
+	return scenes
+}
+
+// GroundTexture returns the texture the ground is drawn with in the golden scenes, in place of sand.png. It is generated,
+// so that the reference images don't depend on a texture which isn't checked in, and is a checkerboard of two sandy
+// colours, so that anything wrong with how it is sampled shows up.
+func GroundTexture() image.Image {
+	const size, square = 64, 8
+	light, dark := color.RGBA{222, 196, 140, 255}, color.RGBA{150, 118, 70, 255}
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if (x/square+y/square)%2 == 0 {
+				img.SetRGBA(x, y, light)
+			} else {
+				img.SetRGBA(x, y, dark)
+			}
+		}
+	}
+	return img
+}
+
+// Options controls how closely a rendered image must match its reference.
+type Options struct {
+	// Threshold is how different, from 0 to 1, two pixels must look before they count as different. Differences are
+	// measured in the YIQ colour space, which weighs brightness more than hue as the eye does.
+	Threshold float64
+	// MaxDifferent is the fraction of pixels which can be different before the image doesn't match.
+	MaxDifferent float64
+}
+
+// DefaultOptions allows for the tiny differences between GPUs and drivers, but not for anything visible.
+var DefaultOptions = Options{Threshold: 0.1, MaxDifferent: 0.001}
+
+// Result is the outcome of comparing a rendered image against its reference.
+type Result struct {
+	Name string
+	// Different is how many pixels looked different, out of Total.
+	Different, Total int
+	Passed           bool
+	// Updated is whether the reference was written rather than compared against.
+	Updated bool
+}
+
+// String returns a one line summary of this Result.
+func (r Result) String() string {
+	switch {
+	case r.Updated:
+		return fmt.Sprintf("UPDATED %s", r.Name)
+	case r.Passed:
+		return fmt.Sprintf("PASS %s (%d of %d pixels different)", r.Name, r.Different, r.Total)
+	default:
+		return fmt.Sprintf("FAIL %s (%d of %d pixels different)", r.Name, r.Different, r.Total)
+	}
+}
+
+// Check compares actual against the reference image called name in dir, or writes it as the reference if update is
+// true. If they don't match, actual and an image highlighting the differences are written alongside the reference as
+// name.actual.png and name.diff.png, and removed again once they do.
+func Check(dir, name string, actual image.Image, update bool, o Options) (Result, error) {
+	file := filepath.Join(dir, name+".png")
+	bounds := actual.Bounds()
+	r := Result{Name: name, Total: bounds.Dx() * bounds.Dy()}
+
+	reference, err := readPNG(file)
+	if os.IsNotExist(err) && !update {
+		return r, fmt.Errorf("there is no reference image %q, run with update to create it", file)
+	}
+	if update {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return r, fmt.Errorf("failed to create golden directory %q: %v", dir, err)
+		}
+		r.Updated, r.Passed = true, true
+		return r, writePNG(file, actual)
+	}
+	if err != nil {
+		return r, err
+	}
+
+	diff, different, err := Compare(actual, reference, o.Threshold)
+	if err != nil {
+		return r, fmt.Errorf("failed to compare against %q: %v", file, err)
+	}
+	r.Different = different
+	r.Passed = float64(different) <= o.MaxDifferent*float64(r.Total)
+	if r.Passed {
+		os.Remove(filepath.Join(dir, name+".actual.png"))
+		os.Remove(filepath.Join(dir, name+".diff.png"))
+	} else {
+		if err := writePNG(filepath.Join(dir, name+".actual.png"), actual); err != nil {
+			return r, err
+		}
+		if err := writePNG(filepath.Join(dir, name+".diff.png"), diff); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// Compare returns how many pixels of a look different from b, by more than threshold from 0 to 1, along with an image
+// showing them in red over a faded copy of b.
+func Compare(a, b image.Image, threshold float64) (*image.RGBA, int, error) {
+	ab, bb := a.Bounds(), b.Bounds()
+	if ab.Dx() != bb.Dx() || ab.Dy() != bb.Dy() {
+		return nil, 0, fmt.Errorf("sizes don't match: %dx%d and %dx%d", ab.Dx(), ab.Dy(), bb.Dx(), bb.Dy())
+	}
+	diff := image.NewRGBA(image.Rect(0, 0, ab.Dx(), ab.Dy()))
+	different := 0
+	for y := 0; y < ab.Dy(); y++ {
+		for x := 0; x < ab.Dx(); x++ {
+			ca := a.At(ab.Min.X+x, ab.Min.Y+y)
+			cb := b.At(bb.Min.X+x, bb.Min.Y+y)
+			if delta(ca, cb) > threshold*threshold*maxDelta {
+				different++
+				diff.Set(x, y, color.RGBA{255, 0, 0, 255})
+				continue
+			}
+			grey := color.GrayModel.Convert(cb).(color.Gray)
+			faded := uint8(255 - (255-int(grey.Y))/4)
+			diff.Set(x, y, color.RGBA{faded, faded, faded, 255})
+		}
+	}
+	return diff, different, nil
+}
+
+// delta returns the squared distance between two colours in the YIQ colour space, which is between 0 and maxDelta.
+func delta(a, b color.Color) float64 {
+	ya, ia, qa := yiq(a)
+	yb, ib, qb := yiq(b)
+	dy, di, dq := ya-yb, ia-ib, qa-qb
+	return 0.5053*dy*dy + 0.299*di*di + 0.1957*dq*dq
+}
+
+// yiq converts c, blended over white by its alpha, into the YIQ colour space with channels from 0 to 255.
+func yiq(c color.Color) (float64, float64, float64) {
+	r, g, b, a := c.RGBA()
+	blend := func(v uint32) float64 {
+		return 255 + (float64(v)-float64(a))/257
+	}
+	rf, gf, bf := blend(r), blend(g), blend(b)
+	y := 0.29889531*rf + 0.58662247*gf + 0.11448223*bf
+	i := 0.59597799*rf - 0.27417610*gf - 0.32180189*bf
+	q := 0.21147017*rf - 0.52261711*gf + 0.31114694*bf
+	return y, i, q
+}
+
+// Summary returns how many of results failed, and a line describing the overall outcome.
+func Summary(results []Result) (int, string) {
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return failed, fmt.Sprintf("%d of %d golden images didn't match", failed, len(results))
+	}
+	return 0, fmt.Sprintf("All %d golden images matched", len(results))
+}
+
+func readPNG(file string) (image.Image, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reference image %q: %v", file, err)
+	}
+	return img, nil
+}
+
+func writePNG(file string, img image.Image) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %v", file, err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %q: %v", file, err)
+	}
+	return f.Close()
+}
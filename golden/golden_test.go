@@ -0,0 +1,136 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// filled returns a w by h image entirely of c.
+func filled(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestDelta(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	if got := delta(color.RGBA{0, 255, 255, 255}, color.RGBA{255, 0, 0, 255}); got < maxDelta-1 || got > maxDelta+1 {
+		t.Errorf("cyan and red are %v apart, want %v", got, maxDelta)
+	}
+	if got := delta(color.RGBA{0, 0, 0, 255}, white); got > maxDelta {
+		t.Errorf("black and white are %v apart, which is more than %v", got, maxDelta)
+	}
+	if got := delta(color.RGBA{12, 200, 99, 255}, color.RGBA{12, 200, 99, 255}); got != 0 {
+		t.Errorf("a colour is %v from itself, want 0", got)
+	}
+	// Anything fully transparent is blended into the white behind it.
+	if got := delta(color.RGBA{}, white); got != 0 {
+		t.Errorf("transparent is %v from white, want 0", got)
+	}
+	// Making a colour brighter looks more different than turning it to another hue of the same brightness.
+	grey := color.RGBA{128, 128, 128, 255}
+	brighter := color.RGBA{148, 148, 148, 255}
+	hue := color.RGBA{148, 118, 128, 255}
+	if delta(grey, brighter) <= delta(grey, hue) {
+		t.Errorf("brightening grey is %v from it, which isn't more than changing its hue at %v", delta(grey, brighter), delta(grey, hue))
+	}
+}
+
+func TestCompareIdentical(t *testing.T) {
+	a := filled(4, 3, color.RGBA{10, 20, 30, 255})
+	// The bounds don't need to start at the same place, only be the same size.
+	b := filled(5, 4, color.RGBA{10, 20, 30, 255}).SubImage(image.Rect(1, 1, 5, 4))
+
+	diff, different, err := Compare(a, b, DefaultOptions.Threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if different != 0 {
+		t.Errorf("got %d different pixels, want 0", different)
+	}
+	if got := diff.Bounds(); got != image.Rect(0, 0, 4, 3) {
+		t.Errorf("got a diff image of %v, want %v", got, image.Rect(0, 0, 4, 3))
+	}
+}
+
+func TestCompareSizeMismatch(t *testing.T) {
+	if _, _, err := Compare(filled(4, 3, color.White), filled(3, 4, color.White), DefaultOptions.Threshold); err == nil {
+		t.Error("comparing a 4x3 image with a 3x4 one didn't fail")
+	}
+}
+
+func TestCompareThreshold(t *testing.T) {
+	grey := color.RGBA{128, 128, 128, 255}
+	a, b := filled(3, 1, grey), filled(3, 1, grey)
+	a.Set(1, 0, color.RGBA{131, 128, 128, 255})
+	a.Set(2, 0, color.RGBA{255, 0, 0, 255})
+
+	diff, different, err := Compare(a, b, DefaultOptions.Threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if different != 1 {
+		t.Errorf("got %d different pixels, want 1", different)
+	}
+	red := color.RGBA{255, 0, 0, 255}
+	if got := diff.At(2, 0); got != red {
+		t.Errorf("the different pixel is %v in the diff image, want %v", got, red)
+	}
+	for x := 0; x < 2; x++ {
+		if got := diff.At(x, 0).(color.RGBA); got.R != got.G || got.G != got.B {
+			t.Errorf("pixel %d, which isn't different, is %v in the diff image, want grey", x, got)
+		}
+	}
+
+	if _, different, _ := Compare(a, b, 0); different != 2 {
+		t.Errorf("with no threshold got %d different pixels, want 2", different)
+	}
+}
+
+// TestScenes builds the engine and runs it with -golden against the reference images in testdata, skipping when
+// there's no OpenGL context to render with.
+func TestScenes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("rendering the golden scenes builds and runs the engine")
+	}
+	testdata, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := filepath.Join(t.TempDir(), "engine")
+	if out, err := exec.Command("go", "build", "-o", engine, "..").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build the engine: %v\n%s", err, out)
+	}
+
+	cmd := exec.Command(engine, "-golden", testdata)
+	cmd.Dir = ".."
+	out, err := cmd.CombinedOutput()
+	// The engine reports the OpenGL version once it has a context, which it can't get without a display or driver.
+	if !regexp.MustCompile(`OpenGL version \S`).Match(out) {
+		t.Skipf("there's no OpenGL context to render with:\n%s", out)
+	}
+	if err != nil && !strings.Contains(string(out), "golden images") {
+		t.Fatalf("the engine failed before it rendered the scenes: %v\n%s", err, out)
+	}
+
+	for _, s := range Scenes() {
+		t.Run(s.Name, func(t *testing.T) {
+			if _, err := os.Stat(filepath.Join(testdata, s.Name+".png")); os.IsNotExist(err) {
+				t.Skipf("there is no reference image, render one with -golden golden/testdata -goldenupdate")
+			}
+			if !regexp.MustCompile(`PASS ` + s.Name + ` `).Match(out) {
+				t.Errorf("%s didn't match its reference, see testdata/%s.diff.png:\n%s", s.Name, s.Name, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,478 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+var (
+	// ActionTopic receives an ActionInput every frame in which at least one action or axis is active. Layers subscribe to
+	// it with SubscribeActions, rather than consuming the whole ActionInput, so that they only take the actions they
+	// handle.
+	ActionTopic = messagebus.NewTopic[ActionInput]("action")
+
+	actionBindings = make(map[string][]Chord)
+	axisBindings   = make(map[string]axisBinding)
+	// boundMods is every set of modifiers which is bound along with each control.
+	boundMods  = make(map[Control][]glfw.ModifierKey)
+	bindingsMu sync.Mutex
+)
+
+func init() {
+	if err := SetBindings(DefaultBindings()); err != nil {
+		panic(err)
+	}
+}
+
+// ActionInput is message data which is sent every frame in which at least one action or axis is active. Its methods leave
+// out every action and axis which a higher priority handler has consumed, while its fields are everything that was active.
+type ActionInput struct {
+	// Held is every action which has at least one of its controls held down.
+	Held []string
+	// PressedThisFrame is every action which had one of its controls go down since the previous frame.
+	PressedThisFrame []string
+	// ReleasedThisFrame is every action which had one of its controls go up since the previous frame.
+	ReleasedThisFrame []string
+	// Axes is the value of every axis which is not at rest, in the range [-1, 1].
+	Axes map[string]float32
+
+	// consumed is every action and axis which has been consumed, which is shared by every copy of this ActionInput.
+	consumed map[string]bool
+}
+
+// UnmarshalJSON decodes an ActionInput, such as one which is being replayed, so that its actions can be consumed.
+func (a *ActionInput) UnmarshalJSON(data []byte) error {
+	type fields ActionInput
+	if err := json.Unmarshal(data, (*fields)(a)); err != nil {
+		return err
+	}
+	a.consumed = make(map[string]bool)
+	return nil
+}
+
+// SubscribeActions registers h to be called with every ActionInput at the provided priority, once every higher priority
+// layer has consumed the actions it handles. Nothing is consumed unless h calls Consume.
+func SubscribeActions(p messagebus.Priority, h func(ActionInput)) *messagebus.Subscription {
+	return ActionTopic.SubscribeWithPriority(p, func(a ActionInput) bool {
+		h(a)
+		return false
+	})
+}
+
+// Consume hides the provided actions and axes from every handler with a lower priority than the caller, leaving the
+// rest of them for the layers below.
+func (a ActionInput) Consume(names ...string) {
+	if a.consumed == nil {
+		return
+	}
+	for _, name := range names {
+		a.consumed[name] = true
+	}
+}
+
+// IsHeld returns whether the provided action has at least one of its controls held down.
+func (a ActionInput) IsHeld(action string) bool {
+	return !a.consumed[action] && contains(a.Held, action)
+}
+
+// WasPressed returns whether the provided action had one of its controls go down since the previous frame.
+func (a ActionInput) WasPressed(action string) bool {
+	return !a.consumed[action] && contains(a.PressedThisFrame, action)
+}
+
+// WasReleased returns whether the provided action had one of its controls go up since the previous frame.
+func (a ActionInput) WasReleased(action string) bool {
+	return !a.consumed[action] && contains(a.ReleasedThisFrame, action)
+}
+
+// Axis returns the value of the provided axis in the range [-1, 1].
+func (a ActionInput) Axis(axis string) float32 {
+	if a.consumed[axis] {
+		return 0
+	}
+	return a.Axes[axis]
+}
+
+func contains(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Bindings is the set of controls bound to every action and axis, as stored in a bindings file. Controls are named as
+// accepted by ParseChord, so they may require modifiers, such as "Ctrl+S".
+type Bindings struct {
+	Actions map[string][]string    `json:"actions"`
+	Axes    map[string]AxisBinding `json:"axes"`
+	// Joystick configures the dead zones and response curve of every joystick axis. The default is used if it is omitted.
+	Joystick *JoystickConfig `json:"joystick,omitempty"`
+}
+
+// AxisBinding is the controls which move an axis towards 1 and -1 respectively, and the joystick axes which move it by
+// however far they are pushed. Joystick axes are named as accepted by ParseJoystickAxis.
+type AxisBinding struct {
+	Positive []string `json:"positive"`
+	Negative []string `json:"negative"`
+	Analog   []string `json:"analog,omitempty"`
+}
+
+type axisBinding struct {
+	positive, negative []Chord
+	analog             []JoystickAxis
+}
+
+// ConflictError is returned when a control or joystick axis would be bound to more than one action or axis.
+type ConflictError struct {
+	Control           string
+	Existing, Binding string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v is bound to both %q and %q", e.Control, e.Existing, e.Binding)
+}
+
+// DefaultBindings returns the bindings used when no bindings file has been loaded.
+func DefaultBindings() Bindings {
+	return Bindings{
+		Actions: map[string][]string{
+			"Quit":                {"Escape"},
+			"ShowDepthPip":        {"PageUp"},
+			"HideDepthPip":        {"PageDown"},
+			"RenderMode0":         {"F1"},
+			"RenderMode1":         {"F2"},
+			"RenderMode2":         {"F3"},
+			"RenderMode3":         {"F4"},
+			"RenderMode4":         {"F5"},
+			"SpawnPointLight":     {"L"},
+			"PipCascade0":         {"KP1"},
+			"PipCascade1":         {"KP2"},
+			"PipCascade2":         {"KP3"},
+			"PipDepthMap":         {"KP9"},
+			"ToggleCursorCapture": {"Tab"},
+			"ToggleFullscreen":    {"Alt+Enter"},
+			"ToggleBorderless":    {"F11"},
+			"NextCamera":          {"C"},
+			"SaveBookmark":        {"P"},
+			"NextBookmark":        {"RightBracket"},
+			"PreviousBookmark":    {"LeftBracket"},
+			"RestoreBookmark":     {"R"},
+			"Sprint":              {"LeftShift", "Joy1Button8"},
+			"Screenshot":          {"F12"},
+			"ScreenshotPip":       {"F10"},
+			"ToggleFrameCapture":  {"F9"},
+		},
+		Axes: map[string]AxisBinding{
+			"MoveForward": {Positive: []string{"W"}, Negative: []string{"S"}, Analog: []string{"-Joy1Axis1"}},
+			"MoveRight":   {Positive: []string{"D"}, Negative: []string{"A"}, Analog: []string{"Joy1Axis0"}},
+			"MoveUp":      {Positive: []string{"Space"}, Negative: []string{"LeftControl"}},
+			"Roll":        {Positive: []string{"E"}, Negative: []string{"Q"}},
+			"LookRight":   {Analog: []string{"Joy1Axis2"}},
+			"LookUp":      {Analog: []string{"-Joy1Axis3"}},
+		},
+	}
+}
+
+// LoadBindings replaces the bindings with the defaults, overridden by the provided JSON bindings file. Each action or
+// axis in the file replaces its default binding, so the file only needs to name the ones which are changed, and one which
+// is bound to nothing is unbound.
+func LoadBindings(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read bindings %q: %v", file, err)
+	}
+	var overrides Bindings
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("failed to parse bindings %q: %v", file, err)
+	}
+	b := DefaultBindings()
+	joystick := DefaultJoystickConfig()
+	b.Joystick = &joystick
+	for action, controls := range overrides.Actions {
+		b.Actions[action] = controls
+	}
+	for axis, binding := range overrides.Axes {
+		b.Axes[axis] = binding
+	}
+	if overrides.Joystick != nil {
+		b.Joystick = overrides.Joystick
+	}
+	if err := SetBindings(b); err != nil {
+		return fmt.Errorf("invalid bindings %q: %v", file, err)
+	}
+	return nil
+}
+
+// SaveBindings writes the current bindings to the provided file, in the format read by LoadBindings.
+func SaveBindings(file string) error {
+	data, err := json.MarshalIndent(GetBindings(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+// SetBindings replaces every binding with the provided ones. Nothing is changed if any control is unknown, or is bound
+// to more than one action or axis. Chords which overlap, such as Alt+Enter and Enter, are allowed since only the more
+// specific one is active while both are held, but each overlap is logged in case it wasn't intended.
+func SetBindings(b Bindings) error {
+	actions := make(map[string][]Chord)
+	axes := make(map[string]axisBinding)
+	bound := make(map[Chord]string)
+	boundAnalog := make(map[JoystickAxis]string)
+
+	// Sorted so that the same conflict is reported every time.
+	for _, action := range sortedKeys(b.Actions) {
+		controls, err := parseChords(action, b.Actions[action], bound)
+		if err != nil {
+			return err
+		}
+		actions[action] = controls
+	}
+	for _, axis := range sortedKeys(b.Axes) {
+		positive, err := parseChords(axis, b.Axes[axis].Positive, bound)
+		if err != nil {
+			return err
+		}
+		negative, err := parseChords(axis, b.Axes[axis].Negative, bound)
+		if err != nil {
+			return err
+		}
+		analog, err := parseJoystickAxes(axis, b.Axes[axis].Analog, boundAnalog)
+		if err != nil {
+			return err
+		}
+		axes[axis] = axisBinding{positive, negative, analog}
+	}
+
+	if b.Joystick != nil {
+		if err := SetJoystickConfig(*b.Joystick); err != nil {
+			return err
+		}
+	}
+
+	mods := make(map[Control][]glfw.ModifierKey)
+	for ch := range bound {
+		mods[ch.Control] = append(mods[ch.Control], ch.Mods)
+	}
+	for _, o := range overlaps(bound) {
+		logf("%v", o)
+	}
+
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+	actionBindings = actions
+	axisBindings = axes
+	boundMods = mods
+	return nil
+}
+
+// overlaps describes every pair of chords which are bound to different actions or axes, where one is on the same
+// control as the other but needs more modifiers, so that it takes over while they are held.
+func overlaps(bound map[Chord]string) []string {
+	var found []string
+	for specific, binding := range bound {
+		for ch, other := range bound {
+			if ch.Control == specific.Control && ch.Mods != specific.Mods && specific.Mods&ch.Mods == ch.Mods && other != binding {
+				found = append(found, fmt.Sprintf("%v (%q) takes over from %v (%q) while both are held", specific, binding, ch, other))
+			}
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// GetBindings returns a copy of the current bindings.
+func GetBindings() Bindings {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+	joystick := GetJoystickConfig()
+	b := Bindings{Actions: make(map[string][]string), Axes: make(map[string]AxisBinding), Joystick: &joystick}
+	for action, controls := range actionBindings {
+		b.Actions[action] = chordNames(controls)
+	}
+	for axis, controls := range axisBindings {
+		b.Axes[axis] = AxisBinding{Positive: chordNames(controls.positive), Negative: chordNames(controls.negative), Analog: joystickAxisNames(controls.analog)}
+	}
+	return b
+}
+
+// BindAction replaces the controls bound to the provided action at runtime. A *ConflictError is returned, and nothing is
+// changed, if any of the controls is already bound to a different action or axis.
+func BindAction(action string, controls ...string) error {
+	b := GetBindings()
+	b.Actions[action] = controls
+	return SetBindings(b)
+}
+
+// BindAxis replaces the controls bound to the provided axis at runtime, keeping any joystick axes bound to it. A
+// *ConflictError is returned, and nothing is changed, if any of the controls is already bound to a different action or
+// axis.
+func BindAxis(axis string, positive, negative []string) error {
+	b := GetBindings()
+	b.Axes[axis] = AxisBinding{Positive: positive, Negative: negative, Analog: b.Axes[axis].Analog}
+	return SetBindings(b)
+}
+
+// BindJoystickAxis replaces the joystick axes bound to the provided axis at runtime, keeping any controls bound to it. A
+// *ConflictError is returned, and nothing is changed, if any of the joystick axes is already bound to a different axis.
+func BindJoystickAxis(axis string, analog ...string) error {
+	b := GetBindings()
+	binding := b.Axes[axis]
+	binding.Analog = analog
+	b.Axes[axis] = binding
+	return SetBindings(b)
+}
+
+func parseChords(binding string, names []string, bound map[Chord]string) ([]Chord, error) {
+	chords := make([]Chord, 0, len(names))
+	for _, name := range names {
+		ch, err := ParseChord(name)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", binding, err)
+		}
+		if existing, ok := bound[ch]; ok && existing != binding {
+			return nil, &ConflictError{Control: ch.String(), Existing: existing, Binding: binding}
+		}
+		bound[ch] = binding
+		chords = append(chords, ch)
+	}
+	return chords, nil
+}
+
+// parseJoystickAxes is parseChords for joystick axes. An axis conflicts with itself even if only one is inverted.
+func parseJoystickAxes(binding string, names []string, bound map[JoystickAxis]string) ([]JoystickAxis, error) {
+	axes := make([]JoystickAxis, 0, len(names))
+	for _, name := range names {
+		a, err := ParseJoystickAxis(name)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", binding, err)
+		}
+		key := JoystickAxis{Joystick: a.Joystick, Axis: a.Axis}
+		if existing, ok := bound[key]; ok && existing != binding {
+			return nil, &ConflictError{Control: key.String(), Existing: existing, Binding: binding}
+		}
+		bound[key] = binding
+		axes = append(axes, a)
+	}
+	return axes, nil
+}
+
+func joystickAxisNames(axes []JoystickAxis) []string {
+	if len(axes) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(axes))
+	for _, a := range axes {
+		names = append(names, a.String())
+	}
+	return names
+}
+
+func chordNames(chords []Chord) []string {
+	names := make([]string, 0, len(chords))
+	for _, ch := range chords {
+		names = append(names, ch.String())
+	}
+	return names
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// updateActions publishes the state of every action and axis, based on the current state of every control and the
+// modifiers which are held down.
+func updateActions(mods glfw.ModifierKey) {
+	a := ActionInput{Axes: make(map[string]float32), consumed: make(map[string]bool)}
+
+	bindingsMu.Lock()
+	for action, chords := range actionBindings {
+		if anyChord(chords, mods, down[:]) {
+			a.Held = append(a.Held, action)
+		}
+		if anyChord(chords, mods, downThisFrame[:]) {
+			a.PressedThisFrame = append(a.PressedThisFrame, action)
+		}
+		// Releasing a modifier first shouldn't swallow the release of the chord's control.
+		if anyReleased(chords) {
+			a.ReleasedThisFrame = append(a.ReleasedThisFrame, action)
+		}
+	}
+	joystickMu.Lock()
+	for axis, chords := range axisBindings {
+		var v float64
+		if anyChord(chords.positive, mods, down[:]) {
+			v++
+		}
+		if anyChord(chords.negative, mods, down[:]) {
+			v--
+		}
+		for _, j := range chords.analog {
+			v += j.value()
+		}
+		if v != 0 {
+			a.Axes[axis] = float32(math.Max(-1, math.Min(v, 1)))
+		}
+	}
+	joystickMu.Unlock()
+	bindingsMu.Unlock()
+
+	if len(a.Held) > 0 || len(a.PressedThisFrame) > 0 || len(a.ReleasedThisFrame) > 0 || len(a.Axes) > 0 {
+		sort.Strings(a.Held)
+		sort.Strings(a.PressedThisFrame)
+		sort.Strings(a.ReleasedThisFrame)
+		ActionTopic.Publish(a)
+	}
+}
+
+// anyChord returns whether any of the chords has its control set in state while all of its modifiers are held, and no
+// more specific chord on the same control is bound and held. It must be called with bindingsMu held.
+func anyChord(chords []Chord, mods glfw.ModifierKey, state []bool) bool {
+	for _, ch := range chords {
+		if state[ch.Control] && mods&ch.Mods == ch.Mods && !shadowed(ch, mods) {
+			return true
+		}
+	}
+	return false
+}
+
+// shadowed returns whether a chord which needs more modifiers than ch is bound on the same control, and all of its
+// modifiers are held. It must be called with bindingsMu held.
+func shadowed(ch Chord, mods glfw.ModifierKey) bool {
+	for _, m := range boundMods[ch.Control] {
+		if m != ch.Mods && m&ch.Mods == ch.Mods && mods&m == m {
+			return true
+		}
+	}
+	return false
+}
+
+// anyReleased returns whether any of the chords had its control go up since the previous frame, whichever modifiers
+// are held.
+func anyReleased(chords []Chord) bool {
+	for _, ch := range chords {
+		if upThisFrame[ch.Control] {
+			return true
+		}
+	}
+	return false
+}
+
+func logf(format string, a ...interface{}) {
+	messagebus.SendAsync(&messagebus.Message{System: "Input", Type: "log", Data1: fmt.Sprintf(format, a...)})
+}
@@ -0,0 +1,179 @@
+package input
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// layers subscribes a UI layer which consumes Quit and the Look axis, above a game layer which records what it sees.
+func layers(t *testing.T) *ActionInput {
+	var seen ActionInput
+	ui := SubscribeActions(messagebus.PriorityUI, func(a ActionInput) {
+		if !a.WasPressed("Quit") {
+			t.Errorf("the UI layer didn't see Quit pressed")
+		}
+		a.Consume("Quit", "Look")
+	})
+	game := SubscribeActions(messagebus.PriorityGame, func(a ActionInput) {
+		seen = a
+	})
+	t.Cleanup(func() {
+		ui.Unsubscribe()
+		game.Unsubscribe()
+	})
+	return &seen
+}
+
+func checkLayers(t *testing.T, seen ActionInput) {
+	if seen.IsHeld("Quit") || seen.WasPressed("Quit") {
+		t.Errorf("the game layer saw Quit, which the UI layer consumed")
+	}
+	if !seen.IsHeld("MoveForward") || !seen.WasReleased("Jump") {
+		t.Errorf("the game layer didn't see the actions which the UI layer left alone")
+	}
+	if got := seen.Axis("Look"); got != 0 {
+		t.Errorf("the game layer saw Look at %v, which the UI layer consumed", got)
+	}
+	if got := seen.Axis("Strafe"); got != -1 {
+		t.Errorf("the game layer saw Strafe at %v, want -1", got)
+	}
+}
+
+func TestConsumeHidesOnlyTheConsumedActions(t *testing.T) {
+	seen := layers(t)
+
+	ActionTopic.Publish(ActionInput{
+		Held:              []string{"MoveForward", "Quit"},
+		PressedThisFrame:  []string{"Quit"},
+		ReleasedThisFrame: []string{"Jump"},
+		Axes:              map[string]float32{"Look": 0.5, "Strafe": -1},
+		consumed:          make(map[string]bool),
+	})
+
+	checkLayers(t, *seen)
+}
+
+func TestConsumeReplayedActions(t *testing.T) {
+	seen := layers(t)
+
+	var a ActionInput
+	recorded := `{"Held":["MoveForward","Quit"],"PressedThisFrame":["Quit"],"ReleasedThisFrame":["Jump"],"Axes":{"Look":0.5,"Strafe":-1}}`
+	if err := json.Unmarshal([]byte(recorded), &a); err != nil {
+		t.Fatal(err)
+	}
+	ActionTopic.Publish(a)
+
+	checkLayers(t, *seen)
+}
+
+func TestLoadBindingsOverridesTheDefaults(t *testing.T) {
+	t.Cleanup(func() {
+		if err := SetBindings(DefaultBindings()); err != nil {
+			t.Fatal(err)
+		}
+	})
+	file := filepath.Join(t.TempDir(), "bindings.json")
+	overrides := `{"actions": {"Quit": ["Backspace"], "Sprint": []}, "axes": {"Roll": {"positive": ["O"], "negative": ["U"]}}}`
+	if err := ioutil.WriteFile(file, []byte(overrides), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadBindings(file); err != nil {
+		t.Fatal(err)
+	}
+
+	got := GetBindings()
+	expected := DefaultBindings()
+	expected.Actions["Quit"] = []string{"Backspace"}
+	expected.Actions["Sprint"] = []string{}
+	expected.Axes["Roll"] = AxisBinding{Positive: []string{"O"}, Negative: []string{"U"}}
+	if err := SetBindings(expected); err != nil {
+		t.Fatal(err)
+	}
+	want := GetBindings()
+	if !reflect.DeepEqual(got.Actions, want.Actions) {
+		t.Errorf("got actions %v, want %v", got.Actions, want.Actions)
+	}
+	if !reflect.DeepEqual(got.Axes, want.Axes) {
+		t.Errorf("got axes %v, want %v", got.Axes, want.Axes)
+	}
+}
+
+// pressThisFrame publishes the actions for a frame in which c went down while mods were held, and returns what was
+// pressed.
+func pressThisFrame(t *testing.T, c Control, mods glfw.ModifierKey) []string {
+	var pressed []string
+	s := SubscribeActions(messagebus.PriorityGame, func(a ActionInput) { pressed = a.PressedThisFrame })
+	defer s.Unsubscribe()
+	down[c], downThisFrame[c] = true, true
+	defer func() { down[c], downThisFrame[c] = false, false }()
+
+	updateActions(mods)
+	return pressed
+}
+
+func TestMostSpecificChordWins(t *testing.T) {
+	t.Cleanup(func() {
+		if err := SetBindings(DefaultBindings()); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := SetBindings(Bindings{Actions: map[string][]string{"Save": {"Ctrl+S"}, "SaveAll": {"Ctrl+Shift+S"}, "Back": {"S"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := KeyControl(glfw.KeyS)
+	for _, c := range []struct {
+		mods glfw.ModifierKey
+		want string
+	}{
+		{0, "Back"},
+		{glfw.ModAlt, "Back"},
+		{glfw.ModControl, "Save"},
+		{glfw.ModControl | glfw.ModAlt, "Save"},
+		{glfw.ModControl | glfw.ModShift, "SaveAll"},
+		{glfw.ModShift, "Back"},
+	} {
+		if got := pressThisFrame(t, s, c.mods); !reflect.DeepEqual(got, []string{c.want}) {
+			t.Errorf("pressing %v fired %v, want [%v]", Chord{s, c.mods}, got, c.want)
+		}
+	}
+}
+
+func TestSetBindingsReportsOverlaps(t *testing.T) {
+	t.Cleanup(func() {
+		if err := SetBindings(DefaultBindings()); err != nil {
+			t.Fatal(err)
+		}
+	})
+	messagebus.Drain()
+	var got []string
+	l := messagebus.RegisterType("log", func(m *messagebus.Message) {
+		if m.System == "Input" {
+			got = append(got, m.Data1.(string))
+		}
+	})
+	defer l.Unsubscribe()
+
+	b := DefaultBindings()
+	b.Actions["Confirm"] = []string{"Enter"}
+	b.Actions["Save"] = []string{"Ctrl+S"}
+	if err := SetBindings(b); err != nil {
+		t.Fatal(err)
+	}
+	messagebus.Drain()
+
+	want := []string{
+		`Alt+Enter ("ToggleFullscreen") takes over from Enter ("Confirm") while both are held`,
+		`Ctrl+S ("Save") takes over from S ("MoveForward") while both are held`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
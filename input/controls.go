@@ -0,0 +1,215 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+const (
+	keyRange         = glfw.KeyLast + 1
+	mouseButtonRange = glfw.MouseButtonLast + 1
+	// maxScancodes is the number of scancodes which keys glfw doesn't recognize can be bound by.
+	maxScancodes = 512
+	controlRange = Control(keyRange) + Control(mouseButtonRange) + maxJoysticks*maxJoystickButtons + maxScancodes
+)
+
+// Control is a physical key, mouse button or joystick button which can be bound to an action or axis. Keys share their
+// glfw.Key value, mouse buttons follow on after the last key, joystick buttons follow on after the last mouse button, and
+// keys which glfw doesn't recognize follow on after the last joystick button by their scancode.
+type Control int
+
+// These are built during variable initialization, rather than in init, so that they are ready before the default
+// bindings are parsed.
+var controlsByName, namesByControl = buildControlNames()
+
+func buildControlNames() (map[string]Control, map[Control]string) {
+	controlsByName := make(map[string]Control)
+	namesByControl := make(map[Control]string)
+	addControlName := func(name string, c Control) {
+		controlsByName[name] = c
+		namesByControl[c] = name
+	}
+
+	for k := glfw.KeyA; k <= glfw.KeyZ; k++ {
+		addControlName(string(rune('A'+k-glfw.KeyA)), KeyControl(k))
+	}
+	for k := glfw.Key0; k <= glfw.Key9; k++ {
+		addControlName(string(rune('0'+k-glfw.Key0)), KeyControl(k))
+	}
+	for k := glfw.KeyF1; k <= glfw.KeyF25; k++ {
+		addControlName(fmt.Sprintf("F%d", k-glfw.KeyF1+1), KeyControl(k))
+	}
+	for k := glfw.KeyKP0; k <= glfw.KeyKP9; k++ {
+		addControlName(fmt.Sprintf("KP%d", k-glfw.KeyKP0), KeyControl(k))
+	}
+	for name, k := range map[string]glfw.Key{
+		"Space":        glfw.KeySpace,
+		"Apostrophe":   glfw.KeyApostrophe,
+		"Comma":        glfw.KeyComma,
+		"Minus":        glfw.KeyMinus,
+		"Period":       glfw.KeyPeriod,
+		"Slash":        glfw.KeySlash,
+		"Semicolon":    glfw.KeySemicolon,
+		"Equal":        glfw.KeyEqual,
+		"LeftBracket":  glfw.KeyLeftBracket,
+		"Backslash":    glfw.KeyBackslash,
+		"RightBracket": glfw.KeyRightBracket,
+		"GraveAccent":  glfw.KeyGraveAccent,
+		"World1":       glfw.KeyWorld1,
+		"World2":       glfw.KeyWorld2,
+		"Escape":       glfw.KeyEscape,
+		"Enter":        glfw.KeyEnter,
+		"Tab":          glfw.KeyTab,
+		"Backspace":    glfw.KeyBackspace,
+		"Insert":       glfw.KeyInsert,
+		"Delete":       glfw.KeyDelete,
+		"Right":        glfw.KeyRight,
+		"Left":         glfw.KeyLeft,
+		"Down":         glfw.KeyDown,
+		"Up":           glfw.KeyUp,
+		"PageUp":       glfw.KeyPageUp,
+		"PageDown":     glfw.KeyPageDown,
+		"Home":         glfw.KeyHome,
+		"End":          glfw.KeyEnd,
+		"CapsLock":     glfw.KeyCapsLock,
+		"ScrollLock":   glfw.KeyScrollLock,
+		"NumLock":      glfw.KeyNumLock,
+		"PrintScreen":  glfw.KeyPrintScreen,
+		"Pause":        glfw.KeyPause,
+		"KPDecimal":    glfw.KeyKPDecimal,
+		"KPDivide":     glfw.KeyKPDivide,
+		"KPMultiply":   glfw.KeyKPMultiply,
+		"KPSubtract":   glfw.KeyKPSubtract,
+		"KPAdd":        glfw.KeyKPAdd,
+		"KPEnter":      glfw.KeyKPEnter,
+		"KPEqual":      glfw.KeyKPEqual,
+		"LeftShift":    glfw.KeyLeftShift,
+		"LeftControl":  glfw.KeyLeftControl,
+		"LeftAlt":      glfw.KeyLeftAlt,
+		"LeftSuper":    glfw.KeyLeftSuper,
+		"RightShift":   glfw.KeyRightShift,
+		"RightControl": glfw.KeyRightControl,
+		"RightAlt":     glfw.KeyRightAlt,
+		"RightSuper":   glfw.KeyRightSuper,
+		"Menu":         glfw.KeyMenu,
+	} {
+		addControlName(name, KeyControl(k))
+	}
+	for name, b := range map[string]glfw.MouseButton{
+		"MouseLeft":   glfw.MouseButtonLeft,
+		"MouseRight":  glfw.MouseButtonRight,
+		"MouseMiddle": glfw.MouseButtonMiddle,
+		"Mouse4":      glfw.MouseButton4,
+		"Mouse5":      glfw.MouseButton5,
+		"Mouse6":      glfw.MouseButton6,
+		"Mouse7":      glfw.MouseButton7,
+		"Mouse8":      glfw.MouseButton8,
+	} {
+		addControlName(name, MouseButtonControl(b))
+	}
+	for j := 0; j < maxJoysticks; j++ {
+		for b := 0; b < maxJoystickButtons; b++ {
+			addControlName(fmt.Sprintf("Joy%dButton%d", j+1, b), JoystickButtonControl(j, b))
+		}
+	}
+	for sc := 0; sc < maxScancodes; sc++ {
+		addControlName(fmt.Sprintf("Scancode%d", sc), ScancodeControl(sc))
+	}
+	return controlsByName, namesByControl
+}
+
+// KeyControl returns the Control for the provided key.
+func KeyControl(k glfw.Key) Control {
+	return Control(k)
+}
+
+// MouseButtonControl returns the Control for the provided mouse button.
+func MouseButtonControl(b glfw.MouseButton) Control {
+	return Control(keyRange) + Control(b)
+}
+
+// ScancodeControl returns the Control for the key with the provided scancode, which is only used for keys that glfw
+// doesn't recognize.
+func ScancodeControl(scancode int) Control {
+	return Control(keyRange) + Control(mouseButtonRange) + maxJoysticks*maxJoystickButtons + Control(scancode)
+}
+
+// ParseControl returns the Control with the provided name, such as "W", "PageUp", "KP1", "MouseLeft", "Joy1Button0" or
+// "Scancode172".
+func ParseControl(name string) (Control, error) {
+	c, ok := controlsByName[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown control %q", name)
+	}
+	return c, nil
+}
+
+// String returns the name of this Control, as accepted by ParseControl.
+func (c Control) String() string {
+	if name, ok := namesByControl[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Control(%d)", int(c))
+}
+
+// allMods is every modifier which a Chord can require.
+const allMods = glfw.ModControl | glfw.ModShift | glfw.ModAlt | glfw.ModSuper
+
+// modifierNames is in the order modifiers are written when formatting a Chord.
+var modifierNames = []struct {
+	name string
+	mod  glfw.ModifierKey
+}{
+	{"Ctrl", glfw.ModControl},
+	{"Shift", glfw.ModShift},
+	{"Alt", glfw.ModAlt},
+	{"Super", glfw.ModSuper},
+}
+
+// Chord is a Control which must be pressed while holding down a set of modifiers, such as Ctrl+S. Other modifiers may be
+// held too, unless a more specific Chord on the same Control is bound and held, in which case only that one is active.
+// So S is active while Ctrl is held, such as when moving while crouching, but not once Ctrl+S is bound as well.
+type Chord struct {
+	Control Control
+	Mods    glfw.ModifierKey
+}
+
+// ParseChord returns the Chord with the provided name, which is any number of modifiers ("Ctrl", "Shift", "Alt" or
+// "Super") followed by a Control name as accepted by ParseControl, all joined by "+".
+func ParseChord(name string) (Chord, error) {
+	parts := strings.Split(name, "+")
+	var ch Chord
+	for _, part := range parts[:len(parts)-1] {
+		found := false
+		for _, m := range modifierNames {
+			if part == m.name {
+				ch.Mods |= m.mod
+				found = true
+			}
+		}
+		if !found {
+			return Chord{}, fmt.Errorf("unknown modifier %q in %q", part, name)
+		}
+	}
+	c, err := ParseControl(parts[len(parts)-1])
+	if err != nil {
+		return Chord{}, err
+	}
+	ch.Control = c
+	return ch, nil
+}
+
+// String returns the name of this Chord, as accepted by ParseChord.
+func (ch Chord) String() string {
+	var b strings.Builder
+	for _, m := range modifierNames {
+		if ch.Mods&m.mod != 0 {
+			b.WriteString(m.name)
+			b.WriteString("+")
+		}
+	}
+	b.WriteString(ch.Control.String())
+	return b.String()
+}
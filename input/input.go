@@ -1,31 +1,155 @@
 package input
 
 import (
-	"fmt"
-
 	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/brandonnelson3/GameEngine/timer"
 
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
-const (
-	keyRange = glfw.KeyLast
+var (
+	down             [controlRange]bool
+	downThisFrame    [controlRange]bool
+	upThisFrame      [controlRange]bool
+	repeatsThisFrame [controlRange]int
+	downSince        [controlRange]float64
+
+	cursorX, cursorY float64
+	scrollX, scrollY float64
+
+	// heldMods is every modifier which is held down, as of the latest key or mouse button event.
+	heldMods glfw.ModifierKey
 )
 
 var (
-	down          [keyRange]bool
-	downThisFrame [keyRange]bool
+	// KeyTopic receives a KeyInput every frame in which at least one key is held down or released.
+	KeyTopic = messagebus.NewTopic[KeyInput]("key")
+
+	// MouseTopic receives a MouseInput for every mouse cursor position callback.
+	MouseTopic = messagebus.NewTopic[MouseInput]("mouse")
+
+	// MouseButtonTopic receives a MouseButtonInput every frame in which at least one mouse button is held down or released.
+	MouseButtonTopic = messagebus.NewTopic[MouseButtonInput]("mousebutton")
+
+	// ScrollTopic receives a ScrollInput every frame in which the scroll wheel moved.
+	ScrollTopic = messagebus.NewTopic[ScrollInput]("scroll")
+
+	// SnapshotTopic receives a Snapshot of every control at the end of every Update.
+	SnapshotTopic = messagebus.NewTopic[Snapshot]("input")
 )
 
+// KeyInput is message data which is sent every frame in which at least one key is held down or released.
+type KeyInput struct {
+	// Pressed is every key which is currently held down.
+	Pressed []glfw.Key
+	// PressedThisFrame is every key which went down since the previous frame.
+	PressedThisFrame []glfw.Key
+	// ReleasedThisFrame is every key which went up since the previous frame.
+	ReleasedThisFrame []glfw.Key
+}
+
 // MouseInput is message data which is sent for every mouse cursor position callback.
 type MouseInput struct {
 	X, Y float64
 }
 
-// Update calls all of the currently pressed keys.
+// MouseButtonInput is message data which is sent every frame in which at least one mouse button is held down or released.
+type MouseButtonInput struct {
+	// Pressed is every mouse button which is currently held down.
+	Pressed []glfw.MouseButton
+	// PressedThisFrame is every mouse button which went down since the previous frame.
+	PressedThisFrame []glfw.MouseButton
+	// ReleasedThisFrame is every mouse button which went up since the previous frame.
+	ReleasedThisFrame []glfw.MouseButton
+	// X and Y are the position of the cursor within the window, such as for selecting what was clicked on.
+	X, Y float64
+}
+
+// ScrollInput is message data which is sent every frame in which the scroll wheel moved.
+type ScrollInput struct {
+	// X and Y are the total scroll offset since the previous frame. Positive Y is scrolling up, away from the user.
+	X, Y float64
+}
+
+// Snapshot is message data which describes the state of every control which is held down, or changed state, during a
+// single frame.
+type Snapshot struct {
+	// Time is the time at which this Snapshot was taken.
+	Time float64
+	// Mods is every modifier which is currently held down.
+	Mods glfw.ModifierKey
+	// ScrollX and ScrollY are the total scroll offset since the previous frame.
+	ScrollX, ScrollY float64
+	// Controls is the state of every control which is held down or changed state this frame.
+	Controls []ControlState
+}
+
+// ControlState is the state of a single control during a single frame.
+type ControlState struct {
+	Control Control
+	// Down is whether the control is currently held down.
+	Down bool
+	// Pressed is whether the control went down since the previous frame.
+	Pressed bool
+	// Released is whether the control went up since the previous frame.
+	Released bool
+	// Repeats is the number of key repeat events since the previous frame.
+	Repeats int
+	// HeldFor is the number of seconds the control has been held down for.
+	HeldFor float64
+}
+
+func (s Snapshot) get(c Control) ControlState {
+	for _, cs := range s.Controls {
+		if cs.Control == c {
+			return cs
+		}
+	}
+	return ControlState{Control: c}
+}
+
+// IsDown returns whether the provided control is held down.
+func (s Snapshot) IsDown(c Control) bool {
+	return s.get(c).Down
+}
+
+// WasPressed returns whether the provided control went down since the previous frame.
+func (s Snapshot) WasPressed(c Control) bool {
+	return s.get(c).Pressed
+}
+
+// WasReleased returns whether the provided control went up since the previous frame.
+func (s Snapshot) WasReleased(c Control) bool {
+	return s.get(c).Released
+}
+
+// Repeats returns the number of key repeat events the provided control has had since the previous frame.
+func (s Snapshot) Repeats(c Control) int {
+	return s.get(c).Repeats
+}
+
+// HeldFor returns the number of seconds the provided control has been held down for, or 0 if it isn't held down.
+func (s Snapshot) HeldFor(c Control) float64 {
+	return s.get(c).HeldFor
+}
+
+// ChordPressed returns whether the provided chord's control went down since the previous frame while all of its
+// modifiers were held down, such as Ctrl+S.
+func (s Snapshot) ChordPressed(ch Chord) bool {
+	return s.Mods&ch.Mods == ch.Mods && s.WasPressed(ch.Control)
+}
+
+// Update polls every joystick, then publishes the state of every control, and the actions and axes they are bound to. It
+// is expected to be called once per frame, after events have been polled.
 func Update() {
+	pollJoysticks()
+
+	now := timer.GetTime()
+	mods := heldMods
+
 	pressedKeys := make([]glfw.Key, 0, 10)
 	pressedKeysThisFrame := make([]glfw.Key, 0, 10)
+	releasedKeysThisFrame := make([]glfw.Key, 0, 10)
 
 	// glfw.KeySpace is the lowest key.
 	for i := glfw.KeySpace; i < keyRange; i++ {
@@ -34,31 +158,133 @@ func Update() {
 		}
 		if downThisFrame[i] {
 			pressedKeysThisFrame = append(pressedKeysThisFrame, i)
-			downThisFrame[i] = false
 		}
+		if upThisFrame[i] {
+			releasedKeysThisFrame = append(releasedKeysThisFrame, i)
+		}
+	}
+	if len(pressedKeys) > 0 || len(releasedKeysThisFrame) > 0 {
+		KeyTopic.Publish(KeyInput{Pressed: pressedKeys, PressedThisFrame: pressedKeysThisFrame, ReleasedThisFrame: releasedKeysThisFrame})
+	}
+
+	pressedButtons := make([]glfw.MouseButton, 0, 3)
+	pressedButtonsThisFrame := make([]glfw.MouseButton, 0, 3)
+	releasedButtonsThisFrame := make([]glfw.MouseButton, 0, 3)
+	for b := glfw.MouseButton1; b < mouseButtonRange; b++ {
+		c := MouseButtonControl(b)
+		if down[c] {
+			pressedButtons = append(pressedButtons, b)
+		}
+		if downThisFrame[c] {
+			pressedButtonsThisFrame = append(pressedButtonsThisFrame, b)
+		}
+		if upThisFrame[c] {
+			releasedButtonsThisFrame = append(releasedButtonsThisFrame, b)
+		}
+	}
+	if len(pressedButtons) > 0 || len(releasedButtonsThisFrame) > 0 {
+		MouseButtonTopic.Publish(MouseButtonInput{Pressed: pressedButtons, PressedThisFrame: pressedButtonsThisFrame, ReleasedThisFrame: releasedButtonsThisFrame, X: cursorX, Y: cursorY})
+	}
+
+	if scrollX != 0 || scrollY != 0 {
+		ScrollTopic.Publish(ScrollInput{scrollX, scrollY})
+	}
+
+	updateMouseDelta()
+
+	snapshot := Snapshot{Time: now, Mods: mods, ScrollX: scrollX, ScrollY: scrollY}
+	for c := Control(0); c < controlRange; c++ {
+		if !down[c] && !downThisFrame[c] && !upThisFrame[c] && repeatsThisFrame[c] == 0 {
+			continue
+		}
+		cs := ControlState{Control: c, Down: down[c], Pressed: downThisFrame[c], Released: upThisFrame[c], Repeats: repeatsThisFrame[c]}
+		if down[c] {
+			cs.HeldFor = now - downSince[c]
+		}
+		snapshot.Controls = append(snapshot.Controls, cs)
 	}
-	if len(pressedKeys) > 0 {
-		messagebus.SendSync(&messagebus.Message{Type: "key", Data1: pressedKeys, Data2: pressedKeysThisFrame})
+	SnapshotTopic.Publish(snapshot)
+
+	updateActions(mods)
+
+	for i := range downThisFrame {
+		downThisFrame[i] = false
+		upThisFrame[i] = false
+		repeatsThisFrame[i] = 0
 	}
+	scrollX, scrollY = 0, 0
+}
+
+// modifierKeys is the modifier which each modifier key holds down, along with the matching key on the other side of the
+// keyboard.
+var modifierKeys = map[glfw.Key]struct {
+	mod   glfw.ModifierKey
+	other glfw.Key
+}{
+	glfw.KeyLeftControl:  {glfw.ModControl, glfw.KeyRightControl},
+	glfw.KeyRightControl: {glfw.ModControl, glfw.KeyLeftControl},
+	glfw.KeyLeftShift:    {glfw.ModShift, glfw.KeyRightShift},
+	glfw.KeyRightShift:   {glfw.ModShift, glfw.KeyLeftShift},
+	glfw.KeyLeftAlt:      {glfw.ModAlt, glfw.KeyRightAlt},
+	glfw.KeyRightAlt:     {glfw.ModAlt, glfw.KeyLeftAlt},
+	glfw.KeyLeftSuper:    {glfw.ModSuper, glfw.KeyRightSuper},
+	glfw.KeyRightSuper:   {glfw.ModSuper, glfw.KeyLeftSuper},
 }
 
-// KeyCallBack is the function bound to handle key events from OpenGL.
+// KeyCallBack is the function bound to handle key events from OpenGL. Keys which glfw doesn't recognize are tracked by
+// their scancode instead, so that they can still be bound.
 func KeyCallBack(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-	if action == glfw.Press {
-		down[key] = true
-		downThisFrame[key] = true
+	heldMods = mods & allMods
+	// Platforms disagree over whether a modifier key's own event includes its modifier, so that follows the event, while
+	// the key on the other side keeps it held.
+	if k, ok := modifierKeys[key]; ok {
+		if action != glfw.Release || down[k.other] {
+			heldMods |= k.mod
+		} else {
+			heldMods &^= k.mod
+		}
 	}
-	if action == glfw.Release {
-		down[key] = false
+
+	switch {
+	case key != glfw.KeyUnknown:
+		setControl(KeyControl(key), action)
+	case scancode >= 0 && scancode < maxScancodes:
+		setControl(ScancodeControl(scancode), action)
 	}
 }
 
 // MouseButtonCallback is the function bound to handle mouse button events from OpenGL.
-func MouseButtonCallback(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
-	fmt.Printf("Got mouse press: %v\n", button)
+func MouseButtonCallback(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	heldMods = mods & allMods
+	setControl(MouseButtonControl(button), action)
+}
+
+func setControl(c Control, action glfw.Action) {
+	switch action {
+	case glfw.Press:
+		if !down[c] {
+			downSince[c] = timer.GetTime()
+		}
+		down[c] = true
+		downThisFrame[c] = true
+	case glfw.Release:
+		down[c] = false
+		upThisFrame[c] = true
+	case glfw.Repeat:
+		repeatsThisFrame[c]++
+	}
 }
 
 // CursorPosCallback is the function bound to handle mouse movement events from OpenGL.
 func CursorPosCallback(w *glfw.Window, x, y float64) {
-	messagebus.SendSync(&messagebus.Message{Type: "mouse", Data1: MouseInput{x, y}})
+	cursorX, cursorY = x, y
+	accumulateMouseMotion(x, y)
+	MouseTopic.Publish(MouseInput{x, y})
+}
+
+// ScrollCallback is the function bound to handle scroll wheel events from OpenGL. Offsets are accumulated until the next
+// Update.
+func ScrollCallback(w *glfw.Window, xoff, yoff float64) {
+	scrollX += xoff
+	scrollY += yoff
 }
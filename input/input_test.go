@@ -0,0 +1,56 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// resetControls releases every control and modifier once the test is done.
+func resetControls(t *testing.T) {
+	t.Cleanup(func() {
+		down = [controlRange]bool{}
+		downThisFrame = [controlRange]bool{}
+		upThisFrame = [controlRange]bool{}
+		heldMods = 0
+	})
+}
+
+func TestKeyCallBackUsesTheEventMods(t *testing.T) {
+	resetControls(t)
+	key := func(k glfw.Key, action glfw.Action, mods glfw.ModifierKey, want glfw.ModifierKey) {
+		t.Helper()
+		KeyCallBack(nil, k, 0, action, mods)
+		if heldMods != want {
+			t.Errorf("after %v %v with mods %v, held mods are %v, want %v", k, action, mods, heldMods, want)
+		}
+	}
+
+	// Some platforms leave a modifier key out of its own press, and include it in its own release.
+	key(glfw.KeyLeftControl, glfw.Press, 0, glfw.ModControl)
+	key(glfw.KeyS, glfw.Press, glfw.ModControl|glfw.ModNumLock, glfw.ModControl)
+	key(glfw.KeyLeftControl, glfw.Release, glfw.ModControl, 0)
+	// Others include it in its own press, and leave it out of its own release.
+	key(glfw.KeyLeftShift, glfw.Press, glfw.ModShift, glfw.ModShift)
+	key(glfw.KeyRightShift, glfw.Press, glfw.ModShift, glfw.ModShift)
+	key(glfw.KeyLeftShift, glfw.Release, 0, glfw.ModShift)
+	key(glfw.KeyRightShift, glfw.Release, 0, 0)
+	// Modifiers which went down while the window wasn't focused only show up in the event's mods.
+	key(glfw.KeyA, glfw.Press, glfw.ModAlt, glfw.ModAlt)
+}
+
+func TestKeyCallBackTracksUnknownKeysByScancode(t *testing.T) {
+	resetControls(t)
+
+	KeyCallBack(nil, glfw.KeyUnknown, 172, glfw.Press, 0)
+	if c := ScancodeControl(172); !down[c] || !downThisFrame[c] {
+		t.Errorf("%v isn't down after it was pressed", c)
+	}
+	if c, err := ParseControl("Scancode172"); err != nil || c != ScancodeControl(172) {
+		t.Errorf(`ParseControl("Scancode172") returned %v, %v`, c, err)
+	}
+
+	// Scancodes which are out of range are ignored.
+	KeyCallBack(nil, glfw.KeyUnknown, maxScancodes, glfw.Press, 0)
+	KeyCallBack(nil, glfw.KeyUnknown, -1, glfw.Press, 0)
+}
@@ -0,0 +1,225 @@
+package input
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+const (
+	// maxJoysticks is the number of joysticks which are polled.
+	maxJoysticks = 4
+	// maxJoystickButtons is the number of buttons per joystick which can be bound.
+	maxJoystickButtons = 32
+	// maxJoystickAxes is the number of axes per joystick which can be bound.
+	maxJoystickAxes = 16
+)
+
+var (
+	joystickSource JoystickSource = glfwJoystickSource{}
+	joystickConfig                = DefaultJoystickConfig()
+	joystickAxes   [maxJoysticks][maxJoystickAxes]float64
+	joystickMu     sync.Mutex
+)
+
+// JoystickSource provides the raw state of every joystick. It is polled once per Update.
+type JoystickSource interface {
+	// Present returns whether joystick j is connected.
+	Present(j int) bool
+	// Axes returns the position of every axis on joystick j, each in the range [-1, 1].
+	Axes(j int) []float32
+	// Buttons returns whether every button on joystick j is pressed, as glfw.Press or glfw.Release.
+	Buttons(j int) []glfw.Action
+}
+
+// glfwJoystickSource polls joysticks through glfw.
+type glfwJoystickSource struct{}
+
+func (glfwJoystickSource) Present(j int) bool {
+	return glfw.Joystick(j).Present()
+}
+
+func (glfwJoystickSource) Axes(j int) []float32 {
+	return glfw.Joystick(j).GetAxes()
+}
+
+func (glfwJoystickSource) Buttons(j int) []glfw.Action {
+	return glfw.Joystick(j).GetButtons()
+}
+
+// SetJoystickSource replaces where joystick state is polled from, such as with a fake device. Passing nil restores
+// polling through glfw.
+func SetJoystickSource(s JoystickSource) {
+	joystickMu.Lock()
+	defer joystickMu.Unlock()
+	if s == nil {
+		s = glfwJoystickSource{}
+	}
+	joystickSource = s
+}
+
+// JoystickConfig configures how raw joystick axes are turned into axis values.
+type JoystickConfig struct {
+	// Sticks is every pair of axes, by index, which together form a two dimensional stick. Each pair shares a radial dead
+	// zone, so that pushing a stick diagonally doesn't snap to one direction.
+	Sticks [][2]int `json:"sticks"`
+	// DeadZone is the fraction of each axis' or stick's range around the centre which is treated as no input.
+	DeadZone float64 `json:"deadZone"`
+	// Exponent is the response curve applied outside of the dead zone. 1 is linear, and larger values give finer control
+	// near the centre.
+	Exponent float64 `json:"exponent"`
+}
+
+// DefaultJoystickConfig returns the JoystickConfig used when none has been loaded, which suits a typical gamepad with
+// its left stick on axes 0 and 1 and its right stick on axes 2 and 3.
+func DefaultJoystickConfig() JoystickConfig {
+	return JoystickConfig{
+		Sticks:   [][2]int{{0, 1}, {2, 3}},
+		DeadZone: 0.2,
+		Exponent: 2,
+	}
+}
+
+// SetJoystickConfig replaces the JoystickConfig.
+func SetJoystickConfig(c JoystickConfig) error {
+	if c.DeadZone < 0 || c.DeadZone >= 1 {
+		return fmt.Errorf("dead zone %v must be in the range [0, 1)", c.DeadZone)
+	}
+	if c.Exponent <= 0 {
+		return fmt.Errorf("exponent %v must be greater than 0", c.Exponent)
+	}
+	for _, s := range c.Sticks {
+		for _, a := range s {
+			if a < 0 || a >= maxJoystickAxes {
+				return fmt.Errorf("stick axis %d must be in the range [0, %d)", a, maxJoystickAxes)
+			}
+		}
+	}
+	joystickMu.Lock()
+	defer joystickMu.Unlock()
+	joystickConfig = c
+	return nil
+}
+
+// GetJoystickConfig returns the current JoystickConfig.
+func GetJoystickConfig() JoystickConfig {
+	joystickMu.Lock()
+	defer joystickMu.Unlock()
+	return joystickConfig
+}
+
+// JoystickButtonControl returns the Control for button b on joystick j, both counting from 0.
+func JoystickButtonControl(j, b int) Control {
+	return Control(keyRange) + Control(mouseButtonRange) + Control(j*maxJoystickButtons+b)
+}
+
+// JoystickAxis is a single analog axis of a joystick, which can be bound to an axis.
+type JoystickAxis struct {
+	Joystick, Axis int
+	// Inverted flips the direction of the axis, such as for a stick which reports pushing up as negative.
+	Inverted bool
+}
+
+// ParseJoystickAxis returns the JoystickAxis with the provided name, such as "Joy1Axis0", or "-Joy1Axis1" for an
+// inverted axis. Joysticks are numbered from 1, and axes from 0.
+func ParseJoystickAxis(name string) (JoystickAxis, error) {
+	var a JoystickAxis
+	n := name
+	if len(n) > 0 && n[0] == '-' {
+		a.Inverted = true
+		n = n[1:]
+	}
+	var joystick int
+	if _, err := fmt.Sscanf(n, "Joy%dAxis%d", &joystick, &a.Axis); err != nil || fmt.Sprintf("Joy%dAxis%d", joystick, a.Axis) != n {
+		return JoystickAxis{}, fmt.Errorf("unknown joystick axis %q", name)
+	}
+	a.Joystick = joystick - 1
+	if a.Joystick < 0 || a.Joystick >= maxJoysticks || a.Axis < 0 || a.Axis >= maxJoystickAxes {
+		return JoystickAxis{}, fmt.Errorf("joystick axis %q is out of range", name)
+	}
+	return a, nil
+}
+
+// String returns the name of this JoystickAxis, as accepted by ParseJoystickAxis.
+func (a JoystickAxis) String() string {
+	s := fmt.Sprintf("Joy%dAxis%d", a.Joystick+1, a.Axis)
+	if a.Inverted {
+		return "-" + s
+	}
+	return s
+}
+
+// value returns the current value of this axis, with the dead zone and response curve already applied.
+func (a JoystickAxis) value() float64 {
+	v := joystickAxes[a.Joystick][a.Axis]
+	if a.Inverted {
+		return -v
+	}
+	return v
+}
+
+// pollJoysticks reads the state of every joystick from the JoystickSource. Buttons are fed through the same state
+// machine as keys and mouse buttons, and axes have their dead zones and response curves applied.
+func pollJoysticks() {
+	joystickMu.Lock()
+	defer joystickMu.Unlock()
+
+	for j := 0; j < maxJoysticks; j++ {
+		var axes []float32
+		var buttons []glfw.Action
+		if joystickSource.Present(j) {
+			axes = joystickSource.Axes(j)
+			buttons = joystickSource.Buttons(j)
+		}
+
+		for b := 0; b < maxJoystickButtons; b++ {
+			c := JoystickButtonControl(j, b)
+			pressed := b < len(buttons) && buttons[b] == glfw.Press
+			if pressed && !down[c] {
+				setControl(c, glfw.Press)
+			} else if !pressed && down[c] {
+				setControl(c, glfw.Release)
+			}
+		}
+
+		var raw [maxJoystickAxes]float64
+		for a := 0; a < maxJoystickAxes && a < len(axes); a++ {
+			raw[a] = float64(axes[a])
+		}
+		joystickAxes[j] = applyDeadZones(raw, joystickConfig)
+	}
+}
+
+// applyDeadZones applies a radial dead zone to every stick, an axial dead zone to every other axis, and the response
+// curve to all of them.
+func applyDeadZones(raw [maxJoystickAxes]float64, c JoystickConfig) [maxJoystickAxes]float64 {
+	var out [maxJoystickAxes]float64
+	var inStick [maxJoystickAxes]bool
+	for _, s := range c.Sticks {
+		x, y := raw[s[0]], raw[s[1]]
+		m := math.Hypot(x, y)
+		scale := 0.0
+		if m > 0 {
+			scale = response(m, c) / m
+		}
+		out[s[0]], out[s[1]] = x*scale, y*scale
+		inStick[s[0]], inStick[s[1]] = true, true
+	}
+	for a, v := range raw {
+		if inStick[a] {
+			continue
+		}
+		out[a] = math.Copysign(response(math.Abs(v), c), v)
+	}
+	return out
+}
+
+// response maps a magnitude in [0, 1] through the dead zone and response curve.
+func response(m float64, c JoystickConfig) float64 {
+	if m <= c.DeadZone {
+		return 0
+	}
+	return math.Pow(math.Min((m-c.DeadZone)/(1-c.DeadZone), 1), c.Exponent)
+}
@@ -0,0 +1,124 @@
+package input
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// fakeJoystick is a JoystickSource with a single joystick, number 0.
+type fakeJoystick struct {
+	present bool
+	axes    []float32
+	buttons []glfw.Action
+}
+
+func (f *fakeJoystick) Present(j int) bool {
+	return j == 0 && f.present
+}
+
+func (f *fakeJoystick) Axes(j int) []float32 {
+	return f.axes
+}
+
+func (f *fakeJoystick) Buttons(j int) []glfw.Action {
+	return f.buttons
+}
+
+// useFakeJoystick polls a connected fakeJoystick with the provided config until the test is done.
+func useFakeJoystick(t *testing.T, c JoystickConfig) *fakeJoystick {
+	resetControls(t)
+	f := &fakeJoystick{present: true, axes: make([]float32, 6)}
+	SetJoystickSource(f)
+	if err := SetJoystickConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		SetJoystickSource(nil)
+		if err := SetJoystickConfig(DefaultJoystickConfig()); err != nil {
+			t.Fatal(err)
+		}
+		joystickAxes = [maxJoysticks][maxJoystickAxes]float64{}
+	})
+	return f
+}
+
+func TestJoystickDeadZones(t *testing.T) {
+	f := useFakeJoystick(t, JoystickConfig{Sticks: [][2]int{{0, 1}}, DeadZone: 0.2, Exponent: 1})
+
+	for _, c := range []struct {
+		name       string
+		x, y, axis float32
+		want       [3]float64
+	}{
+		{"at rest", 0, 0, 0, [3]float64{0, 0, 0}},
+		{"inside", 0.1, -0.1, 0.15, [3]float64{0, 0, 0}},
+		{"on the edge", 0.2, 0, 0.2, [3]float64{0, 0, 0}},
+		// The dead zone is radial, so a diagonal which is inside it overall stays there even though neither axis is.
+		{"on the edge diagonally", 0.12, 0.16, -0.2, [3]float64{0, 0, 0}},
+		{"rescaled outside", 0.6, 0, -0.6, [3]float64{0.5, 0, -0.5}},
+		// Rescaling keeps the stick's direction.
+		{"rescaled outside diagonally", 0.36, -0.48, 0.6, [3]float64{0.3, -0.4, 0.5}},
+		{"at full", 0, 1, 1, [3]float64{0, 1, 1}},
+		{"past full in the corner", 1, 1, -1, [3]float64{math.Sqrt2 / 2, math.Sqrt2 / 2, -1}},
+	} {
+		f.axes[0], f.axes[1], f.axes[4] = c.x, c.y, c.axis
+		pollJoysticks()
+		got := [3]float64{joystickAxes[0][0], joystickAxes[0][1], joystickAxes[0][4]}
+		for i := range got {
+			if math.Abs(got[i]-c.want[i]) > 1e-6 {
+				t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestJoystickResponseCurve(t *testing.T) {
+	f := useFakeJoystick(t, JoystickConfig{Sticks: [][2]int{{0, 1}}, DeadZone: 0.2, Exponent: 2})
+
+	f.axes[0], f.axes[4] = 0.6, -0.6
+	pollJoysticks()
+
+	if got := joystickAxes[0][0]; math.Abs(got-0.25) > 1e-6 {
+		t.Errorf("stick axis got %v, want 0.25", got)
+	}
+	if got := joystickAxes[0][4]; math.Abs(got+0.25) > 1e-6 {
+		t.Errorf("lone axis got %v, want -0.25", got)
+	}
+}
+
+func TestJoystickDisconnectAndReconnect(t *testing.T) {
+	f := useFakeJoystick(t, DefaultJoystickConfig())
+	button := JoystickButtonControl(0, 1)
+	f.buttons = []glfw.Action{glfw.Release, glfw.Press}
+	f.axes[0] = 1
+
+	pollJoysticks()
+	if !down[button] || !downThisFrame[button] {
+		t.Fatalf("%v isn't down after it was pressed", button)
+	}
+	downThisFrame[button] = false
+
+	// Unplugging the joystick lets go of everything on it.
+	f.present = false
+	pollJoysticks()
+	if down[button] || !upThisFrame[button] {
+		t.Errorf("%v is still down after the joystick was disconnected", button)
+	}
+	if joystickAxes[0][0] != 0 {
+		t.Errorf("axis is at %v after the joystick was disconnected, want 0", joystickAxes[0][0])
+	}
+	upThisFrame[button] = false
+
+	// Plugging it back in with the button still held presses it again.
+	f.present = true
+	pollJoysticks()
+	if !down[button] || !downThisFrame[button] {
+		t.Errorf("%v isn't down after the joystick was reconnected", button)
+	}
+	if joystickAxes[0][0] != 1 {
+		t.Errorf("axis is at %v after the joystick was reconnected, want 1", joystickAxes[0][0])
+	}
+}
@@ -0,0 +1,107 @@
+package input
+
+import (
+	"math"
+	"sync"
+
+	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+const (
+	// minimumMouseMotion is the smallest MouseDelta which is published, so smoothing doesn't publish forever.
+	minimumMouseMotion = 1e-4
+)
+
+var (
+	// MouseDeltaTopic receives a MouseDelta every frame in which the mouse moved while it was captured.
+	MouseDeltaTopic = messagebus.NewTopic[MouseDelta]("mousedelta")
+
+	mouseMu              sync.Mutex
+	captured             bool
+	haveLastCursor       bool
+	lastX, lastY         float64
+	rawDeltaX, rawDeltaY float64
+	smoothedX, smoothedY float64
+	mouseSensitivity     = 1.0
+	mouseSmoothing       = 0.0
+)
+
+// MouseDelta is message data which is sent every frame in which the mouse moved while it was captured. It is the
+// relative motion since the previous frame, with sensitivity and smoothing already applied, so it doesn't depend on the
+// window size or where the cursor is.
+type MouseDelta struct {
+	X, Y float64
+}
+
+// SetMouseCaptured sets whether mouse motion should be turned into MouseDeltas. Released, the cursor is free to be used
+// by tooling without moving anything which is driven by MouseDeltas.
+func SetMouseCaptured(c bool) {
+	mouseMu.Lock()
+	defer mouseMu.Unlock()
+	captured = c
+	resetMouseDelta()
+}
+
+// IsMouseCaptured returns whether mouse motion is currently being turned into MouseDeltas.
+func IsMouseCaptured() bool {
+	mouseMu.Lock()
+	defer mouseMu.Unlock()
+	return captured
+}
+
+// SetMouseSensitivity sets the multiplier applied to mouse motion, in pixels, before it is published as a MouseDelta.
+func SetMouseSensitivity(s float64) {
+	mouseMu.Lock()
+	defer mouseMu.Unlock()
+	mouseSensitivity = s
+}
+
+// SetMouseSmoothing sets how much of the previous frame's MouseDelta is blended into the current one, from 0 for raw
+// unsmoothed motion up to, but not including, 1.
+func SetMouseSmoothing(s float64) {
+	mouseMu.Lock()
+	defer mouseMu.Unlock()
+	mouseSmoothing = math.Max(0, math.Min(s, 0.99))
+}
+
+// FocusCallback is the function bound to handle window focus events from OpenGL. The cursor can move anywhere while the
+// window is unfocused, so that motion is discarded rather than being applied all at once when focus returns.
+func FocusCallback(w *glfw.Window, focused bool) {
+	mouseMu.Lock()
+	defer mouseMu.Unlock()
+	resetMouseDelta()
+}
+
+// resetMouseDelta must be called with mouseMu held.
+func resetMouseDelta() {
+	haveLastCursor = false
+	rawDeltaX, rawDeltaY = 0, 0
+	smoothedX, smoothedY = 0, 0
+}
+
+// accumulateMouseMotion adds the motion from the previous cursor position to (x, y) to this frame's raw delta.
+func accumulateMouseMotion(x, y float64) {
+	mouseMu.Lock()
+	defer mouseMu.Unlock()
+	if haveLastCursor && captured {
+		rawDeltaX += x - lastX
+		rawDeltaY += y - lastY
+	}
+	lastX, lastY = x, y
+	haveLastCursor = true
+}
+
+// updateMouseDelta publishes this frame's MouseDelta, if there was any motion.
+func updateMouseDelta() {
+	mouseMu.Lock()
+	smoothedX = smoothedX*mouseSmoothing + rawDeltaX*mouseSensitivity*(1-mouseSmoothing)
+	smoothedY = smoothedY*mouseSmoothing + rawDeltaY*mouseSensitivity*(1-mouseSmoothing)
+	rawDeltaX, rawDeltaY = 0, 0
+	d := MouseDelta{smoothedX, smoothedY}
+	mouseMu.Unlock()
+
+	if math.Abs(d.X) > minimumMouseMotion || math.Abs(d.Y) > minimumMouseMotion {
+		MouseDeltaTopic.Publish(d)
+	}
+}
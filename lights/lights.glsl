@@ -0,0 +1,21 @@
+// The lights as they are laid out in their buffers, which must match PointLight, VisibleIndex and DirectionalLight in Go.
+//
+// MAXIMUM_POINT_LIGHTS is defined from lights.MaximumPointLights, and is also how many visible light indices each tile
+// has room for.
+
+struct PointLight {
+	vec3 color;
+	float intensity;
+	vec3 position;
+	float radius;
+};
+
+struct VisibleIndex {
+	int index;
+};
+
+struct DirectionalLight {
+	vec3 color;
+	float brightness;
+	vec3 direction;
+};
@@ -1,3 +1,5 @@
+// Package lights manages the scene's lights, whose structs are laid out as the matching ones in lights.glsl so that
+// they can be copied straight into shader storage buffers.
 package lights
 
 import (
@@ -50,11 +52,18 @@ func InitPointLights() {
 
 	// Bind light buffer
 	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, lightBuffer)
-	gl.BufferData(gl.SHADER_STORAGE_BUFFER, MaximumPointLights*int(unsafe.Sizeof(&PointLight{})), unsafe.Pointer(&PointLights), gl.DYNAMIC_DRAW)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, MaximumPointLights*int(unsafe.Sizeof(PointLight{})), unsafe.Pointer(&PointLights), gl.DYNAMIC_DRAW)
 
-	// Bind visible light indices buffer
+	allocateVisibleLightIndices()
+	window.ResizeTopic.Subscribe(func(window.Resize) {
+		allocateVisibleLightIndices()
+	})
+}
+
+// allocateVisibleLightIndices sizes the visible light indices buffer for the number of tiles the window currently needs.
+func allocateVisibleLightIndices() {
 	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, visibleLightIndicesBuffer)
-	gl.BufferData(gl.SHADER_STORAGE_BUFFER, int(window.GetTotalNumTiles())*int(unsafe.Sizeof(&VisibleIndex{}))*MaximumPointLights, nil, gl.STATIC_DRAW)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, int(window.GetTotalNumTiles())*int(unsafe.Sizeof(VisibleIndex{}))*MaximumPointLights, nil, gl.STATIC_DRAW)
 
 	// Unbind for safety.
 	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
@@ -87,7 +96,7 @@ func AddPointLight(position, color mgl32.Vec3, intensity, radius float32) {
 	mu.Unlock()
 
 	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, lightBuffer)
-	gl.BufferData(gl.SHADER_STORAGE_BUFFER, MaximumPointLights*int(unsafe.Sizeof(&PointLight{})), unsafe.Pointer(&PointLights), gl.DYNAMIC_DRAW)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, MaximumPointLights*int(unsafe.Sizeof(PointLight{})), unsafe.Pointer(&PointLights), gl.DYNAMIC_DRAW)
 
 	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
 }
@@ -1,23 +1,33 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"runtime"
 
 	"github.com/go-gl/gl/v4.5-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 
+	"github.com/brandonnelson3/GameEngine/camera"
+	"github.com/brandonnelson3/GameEngine/capture"
+	"github.com/brandonnelson3/GameEngine/config"
 	"github.com/brandonnelson3/GameEngine/depthfragmentshader"
 	"github.com/brandonnelson3/GameEngine/depthvertexshader"
 	"github.com/brandonnelson3/GameEngine/fragmentshader"
 	"github.com/brandonnelson3/GameEngine/framerate"
+	"github.com/brandonnelson3/GameEngine/golden"
 	"github.com/brandonnelson3/GameEngine/input"
 	"github.com/brandonnelson3/GameEngine/lightcullingshader"
 	"github.com/brandonnelson3/GameEngine/lights"
 	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/brandonnelson3/GameEngine/offscreen"
 	"github.com/brandonnelson3/GameEngine/pip"
+	"github.com/brandonnelson3/GameEngine/recording"
+	"github.com/brandonnelson3/GameEngine/shader"
 	"github.com/brandonnelson3/GameEngine/textures"
 	"github.com/brandonnelson3/GameEngine/timer"
 	"github.com/brandonnelson3/GameEngine/uniforms"
@@ -30,22 +40,141 @@ const (
 	RadToDeg = 57.295779513082320876798154814105170332405472466564   // N[180/Pi, 50]
 )
 
+var (
+	configFile    = flag.String("config", "engine.json", "Loads engine settings from this file if it exists. Each setting can also be overridden by its own flag, such as -window.width.")
+	bindingsFile  = flag.String("bindings", "bindings.json", "Loads key and mouse bindings from this file if it exists.")
+	bookmarksFile = flag.String("bookmarks", "bookmarks.json", "Loads camera bookmarks from this file if it exists, unless bookmarks have been saved to the user config directory.")
+	recordFile    = flag.String("record", "", "Records every message sent on the messagebus to this file.")
+	replayFile    = flag.String("replay", "", "Replays keyboard and mouse input from a file written by -record, instead of reading it from the window.")
+	pathFile      = flag.String("path", "", "Flies the camera along the spline path in this file, and logs how long it took, such as for a benchmark.")
+	pathStep      = flag.Float64("pathstep", 0, "Advances -path by this many seconds every frame, rather than by the length of the frame, so that every run renders the same frames.")
+	pathQuit      = flag.Bool("pathquit", false, "Quits once -path has finished.")
+	captureDir    = flag.String("capture", "frames", "Directory ToggleFrameCapture writes every frame to, as a numbered sequence of PNG files.")
+	captureAll    = flag.Bool("captureall", false, "Starts capturing every frame to -capture straight away.")
+	frameLimit    = flag.Uint64("frames", 0, "Quits after rendering this many frames, such as to check that everything builds and renders with -window.headless.")
+	goldenDir     = flag.String("golden", "", "Renders canned scenes offscreen, compares them against the reference images in this directory, then quits, failing if any don't match.")
+	goldenUpdate  = flag.Bool("goldenupdate", false, "Overwrites the reference images in -golden with what is rendered, rather than comparing against them.")
+	listMonitors  = flag.Bool("monitors", false, "Lists every monitor and the video modes it supports, for use in the config, then quits.")
+)
+
 func init() {
 	// GLFW event handling must run on the main OS thread
 	runtime.LockOSThread()
+
+	recording.Register(input.KeyTopic)
+	recording.Register(input.ActionTopic)
+	recording.Register(input.MouseTopic)
+	recording.Register(input.MouseButtonTopic)
+	recording.Register(input.ScrollTopic)
+	recording.Register(input.MouseDeltaTopic)
+	recording.Register(input.SnapshotTopic)
+
+	config.RegisterFlags(flag.CommandLine)
 }
 
 func main() {
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+	flag.Parse()
+
+	file := *configFile
+	if _, err := os.Stat(file); err != nil {
+		file = ""
+	}
+	if err := config.Load(file); err != nil {
+		log.Fatalln(err)
+	}
+	if *goldenDir != "" {
+		// The reference images are rendered offscreen at a fixed size, with nothing a local config could change.
+		c := config.Default()
+		c.Window.Headless = true
+		c.Window.Width, c.Window.Height = golden.Width, golden.Height
+		c.Window.Samples = 0
+		c.Framerate.Cap = 0
+		if err := config.Set(c); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	mouse := config.Get().Mouse
+	input.SetMouseSensitivity(mouse.Sensitivity)
+	input.SetMouseSmoothing(mouse.Smoothing)
+
+	if _, err := os.Stat(*bindingsFile); err == nil {
+		if err := input.LoadBindings(*bindingsFile); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	// Saved bookmarks include the checked in ones, so those are only loaded until something is saved.
+	bookmarks := *bookmarksFile
+	if userBookmarks, err := camera.UserBookmarksFile(); err != nil {
+		log.Println("bookmarks won't be saved:", err)
+	} else {
+		camera.SetBookmarksFile(userBookmarks)
+		if _, err := os.Stat(userBookmarks); err == nil {
+			bookmarks = userBookmarks
+		}
+	}
+	if _, err := os.Stat(bookmarks); err == nil {
+		if err := camera.LoadBookmarks(bookmarks); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
 	if err := glfw.Init(); err != nil {
 		log.Fatalln("failed to initialize glfw:", err)
 	}
 	defer glfw.Terminate()
 
-	w := window.Create()
+	if *listMonitors {
+		for i, m := range window.GetMonitors() {
+			fmt.Printf("Monitor %d: %s at %d,%d, %dx%dmm, content scale %v, currently %v\n", i, m.Name, m.X, m.Y, m.PhysicalWidth, m.PhysicalHeight, m.ContentScale, m.Current)
+			for _, vm := range m.Modes {
+				fmt.Printf("\t%v\n", vm)
+			}
+		}
+		return
+	}
+
+	w, err := window.Create()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var player *recording.Player
+	if *replayFile != "" {
+		f, err := os.Open(*replayFile)
+		if err != nil {
+			log.Fatalln("failed to open replay:", err)
+		}
+		defer f.Close()
+		player = recording.NewPlayer(f)
+	} else {
+		w.SetKeyCallback(input.KeyCallBack)
+		w.SetMouseButtonCallback(input.MouseButtonCallback)
+		w.SetCursorPosCallback(input.CursorPosCallback)
+		w.SetScrollCallback(input.ScrollCallback)
+		w.SetFocusCallback(input.FocusCallback)
+	}
+
+	if *recordFile != "" {
+		f, err := os.Create(*recordFile)
+		if err != nil {
+			log.Fatalln("failed to create recording:", err)
+		}
+		defer f.Close()
+		recorder := recording.Start(f)
+		defer func() {
+			if err := recorder.Stop(); err != nil {
+				log.Println("failed to write recording:", err)
+			}
+		}()
+	}
 
-	w.SetKeyCallback(input.KeyCallBack)
-	w.SetMouseButtonCallback(input.MouseButtonCallback)
-	w.SetCursorPosCallback(input.CursorPosCallback)
 	w.MakeContextCurrent()
 
 	// Initialize Glow
@@ -56,15 +185,25 @@ func main() {
 	version := gl.GoStr(gl.GetString(gl.VERSION))
 	fmt.Println("OpenGL version", version)
 
-	// Initially place cursor dead center.
-	window.RecenterCursor()
-
 	// Configure global settings
 	gl.Enable(gl.DEPTH_TEST)
 	gl.DepthFunc(gl.LESS)
 	gl.Enable(gl.MULTISAMPLE)
 	gl.DepthMask(true)
 	gl.ClearColor(0.0, 0.0, 0.0, 1.0)
+	gl.Viewport(0, 0, int32(window.GetWidth()), int32(window.GetHeight()))
+
+	// The scene is rendered into screenFramebuffer, which is the window's unless it is headless.
+	var screenFramebuffer uint32
+	var target *offscreen.Target
+	if window.IsHeadless() {
+		target, err = offscreen.NewTarget(window.GetWidth(), window.GetHeight())
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer target.Delete()
+		screenFramebuffer = target.GetFramebuffer()
+	}
 
 	lights.InitPointLights()
 	lights.InitDirectionalLights()
@@ -74,11 +213,20 @@ func main() {
 		panic(err)
 	}
 
-	sandTexture, err := textures.NewFromPng("sand.png")
+	var sandTexture uint32
+	if *goldenDir != "" {
+		sandTexture, err = textures.New(golden.GroundTexture())
+	} else {
+		sandTexture, err = textures.NewFromPng("sand.png")
+	}
 	if err != nil {
 		panic(err)
 	}
 
+	// Anything the shaders need to agree with the Go side on is defined from it.
+	shader.Define("TILE_SIZE", config.Get().Lighting.TileSize)
+	shader.Define("MAXIMUM_POINT_LIGHTS", lights.MaximumPointLights)
+
 	// Build Depth Pipeline
 	depthVertexShader, err := depthvertexshader.NewDepthVertexShader()
 	if err != nil {
@@ -115,6 +263,15 @@ func main() {
 	vertexShader.AddToPipeline(normalPipeline)
 	fragmentShader.AddToPipeline(normalPipeline)
 	gl.ValidateProgramPipeline(normalPipeline)
+
+	// Every material's variant is built up front, so that drawing with it doesn't stall.
+	crate := material{diffuse: diffuseTexture}
+	sand := material{diffuse: sandTexture, features: []string{"ANTI_TILING"}}
+	for _, m := range []material{crate, sand} {
+		if err := fragmentShader.UseFeatures(m.features...); err != nil {
+			panic(err)
+		}
+	}
 	gl.UseProgram(0)
 	gl.BindProgramPipeline(normalPipeline)
 
@@ -124,9 +281,9 @@ func main() {
 	var csmDepthMap [3]uint32
 	gl.GenTextures(3, &csmDepthMap[0])
 
+	allocateDepthMaps(csmDepthMap[:]...)
 	for _, m := range csmDepthMap {
 		gl.BindTexture(gl.TEXTURE_2D, m)
-		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT, int32(window.Width), int32(window.Height), 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
@@ -144,8 +301,8 @@ func main() {
 	gl.GenFramebuffers(1, &depthMapFBO)
 	var depthMap uint32
 	gl.GenTextures(1, &depthMap)
+	allocateDepthMaps(depthMap)
 	gl.BindTexture(gl.TEXTURE_2D, depthMap)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT, int32(window.Width), int32(window.Height), 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
@@ -160,6 +317,12 @@ func main() {
 
 	pip.Initialize(&depthMap)
 
+	window.ResizeTopic.Subscribe(func(r window.Resize) {
+		gl.Viewport(0, 0, int32(r.Width), int32(r.Height))
+		allocateDepthMaps(depthMap)
+		allocateDepthMaps(csmDepthMap[:]...)
+	})
+
 	// Configure the vertex data
 	var cubeVao uint32
 	gl.GenVertexArrays(1, &cubeVao)
@@ -183,32 +346,78 @@ func main() {
 
 	vertexShader.BindVertexAttributes()
 
-	camera := NewFirstPersonCamera()
-
-	messagebus.RegisterType("key", func(m *messagebus.Message) {
-		pressedKeys := m.Data2.([]glfw.Key)
-		for _, key := range pressedKeys {
-			switch key {
-			case glfw.KeyL:
-				lights.AddPointLight(camera.GetPosition(), mgl32.Vec3{1, 1, 1}, 1, 10)
-			case glfw.KeyKP1:
-				pip.DepthMap = &csmDepthMap[0]
-			case glfw.KeyKP2:
-				pip.DepthMap = &csmDepthMap[1]
-			case glfw.KeyKP3:
-				pip.DepthMap = &csmDepthMap[2]
-			case glfw.KeyKP9:
-				pip.DepthMap = &depthMap
+	firstPersonCamera := camera.NewFirstPersonCamera()
+	firstPersonCamera.SetCollider(sceneCollider(), 0.5)
+	camera.Add("firstperson", firstPersonCamera)
+	// The free fly camera starts off facing the same way as the first person camera.
+	camera.Add("freefly", camera.NewFreeFlyCamera(firstPersonCamera.GetPosition(), firstPersonCamera.GetOrientation()))
+	camera.Add("orbit", camera.NewOrbitCamera(mgl32.Vec3{18, 5, 18}, 40))
+	camera.Add("follow", camera.NewFollowCamera(firstPersonCamera, 8, 3))
+
+	var pathAnimation *camera.Animation
+	var pathStartFrame uint64
+	var pathStartTime float64
+	if *pathFile != "" {
+		path, err := camera.LoadPath(*pathFile)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		pathAnimation = camera.Play(firstPersonCamera, path)
+		pathAnimation.FixedStep = *pathStep
+		pathStartFrame = timer.GetFrameNumber()
+		pathStartTime = timer.GetTime()
+	}
+
+	input.ActionTopic.Subscribe(func(a input.ActionInput) {
+		if a.WasPressed("SpawnPointLight") {
+			lights.AddPointLight(camera.Active().GetPosition(), mgl32.Vec3{1, 1, 1}, 1, 10)
+		}
+		if a.WasPressed("PipCascade0") {
+			pip.DepthMap = &csmDepthMap[0]
+		}
+		if a.WasPressed("PipCascade1") {
+			pip.DepthMap = &csmDepthMap[1]
+		}
+		if a.WasPressed("PipCascade2") {
+			pip.DepthMap = &csmDepthMap[2]
+		}
+		if a.WasPressed("PipDepthMap") {
+			pip.DepthMap = &depthMap
+		}
+		if a.WasPressed("Screenshot") {
+			capture.Screenshot(capture.Framebuffer(screenFramebuffer), capture.ScreenshotFile("screenshot"))
+		}
+		if a.WasPressed("ScreenshotPip") {
+			capture.Screenshot(capture.DepthTexture(*pip.DepthMap), capture.ScreenshotFile("depth"))
+		}
+		if a.WasPressed("ToggleFrameCapture") {
+			if capture.IsCapturingSequence() {
+				capture.StopSequence()
+			} else if err := capture.StartSequence(*captureDir, capture.Framebuffer(screenFramebuffer)); err != nil {
+				log.Println(err)
 			}
 		}
 	})
+	if *captureAll {
+		if err := capture.StartSequence(*captureDir, capture.Framebuffer(screenFramebuffer)); err != nil {
+			log.Fatalln(err)
+		}
+	}
 
-	for !w.ShouldClose() {
-		timer.BeginningOfFrame()
-		framerate.BeginningOfFrame(timer.GetTime())
-		input.Update()
-		camera.Update(timer.GetPreviousFrameLength())
+	// useMaterial switches the fragment shader to the variant m needs, and sets its uniforms for drawing with m.
+	useMaterial := func(m material) {
+		if err := fragmentShader.UseFeatures(m.features...); err != nil {
+			log.Println(err)
+		}
+		fragmentShader.NumTilesX.Set(window.GetNumTilesX())
+		fragmentShader.LightBuffer.Set(lights.GetPointLightBuffer())
+		fragmentShader.VisibleLightIndicesBuffer.Set(lights.GetPointLightVisibleLightIndicesBuffer())
+		fragmentShader.DirectionalLightBuffer.Set(lights.GetDirectionalLightBuffer())
+		fragmentShader.Diffuse.Set(gl.TEXTURE0, 0, m.diffuse)
+	}
 
+	// render draws everything from the provided camera into screenFramebuffer.
+	render := func(view, projection mgl32.Mat4) {
 		// Step 1: Render all shadow maps.
 		gl.BindProgramPipeline(depthPipeline)
 		/*		for i, m := range csmDepthMap {
@@ -231,8 +440,8 @@ func main() {
 		// Step 2: Depth Pass for pointlight culling
 		gl.BindFramebuffer(gl.FRAMEBUFFER, depthMapFBO)
 		gl.Clear(gl.DEPTH_BUFFER_BIT)
-		depthVertexShader.View.Set(camera.GetView())
-		depthVertexShader.Projection.Set(window.GetProjection())
+		depthVertexShader.View.Set(view)
+		depthVertexShader.Projection.Set(projection)
 		gl.BindVertexArray(cubeVao)
 		for x := 0; x < 10; x++ {
 			for y := 0; y < 10; y++ {
@@ -247,10 +456,10 @@ func main() {
 
 		// Step 3: Light Culling
 		lightCullingShader.Use()
-		lightCullingShader.View.Set(camera.GetView())
-		lightCullingShader.Projection.Set(window.GetProjection())
+		lightCullingShader.View.Set(view)
+		lightCullingShader.Projection.Set(projection)
 		lightCullingShader.DepthMap.Set(gl.TEXTURE4, 4, depthMap)
-		lightCullingShader.ScreenSize.Set(uniforms.UIVec2{window.Width, window.Height})
+		lightCullingShader.ScreenSize.Set(uniforms.UIVec2{window.GetWidth(), window.GetHeight()})
 		lightCullingShader.LightCount.Set(lights.GetNumPointLights())
 		lightCullingShader.LightBuffer.Set(lights.GetPointLightBuffer())
 		lightCullingShader.VisibleLightIndicesBuffer.Set(lights.GetPointLightVisibleLightIndicesBuffer())
@@ -259,16 +468,12 @@ func main() {
 		gl.UseProgram(0)
 
 		// Step 4: Normal pass utilizing csm.
-		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, screenFramebuffer)
 		gl.BindProgramPipeline(normalPipeline)
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-		vertexShader.View.Set(camera.GetView())
-		vertexShader.Projection.Set(window.GetProjection())
-		fragmentShader.NumTilesX.Set(window.GetNumTilesX())
-		fragmentShader.LightBuffer.Set(lights.GetPointLightBuffer())
-		fragmentShader.VisibleLightIndicesBuffer.Set(lights.GetPointLightVisibleLightIndicesBuffer())
-		fragmentShader.DirectionalLightBuffer.Set(lights.GetDirectionalLightBuffer())
-		fragmentShader.Diffuse.Set(gl.TEXTURE0, 0, diffuseTexture)
+		vertexShader.View.Set(view)
+		vertexShader.Projection.Set(projection)
+		useMaterial(crate)
 		gl.BindVertexArray(cubeVao)
 		for x := 0; x < 10; x++ {
 			for y := 0; y < 10; y++ {
@@ -281,7 +486,7 @@ func main() {
 		gl.BindTexture(gl.TEXTURE_2D, 0)
 
 		vertexShader.Model.Set(mgl32.Ident4())
-		fragmentShader.Diffuse.Set(gl.TEXTURE0, 0, sandTexture)
+		useMaterial(sand)
 		gl.BindVertexArray(planeVao)
 		gl.DrawArrays(gl.TRIANGLES, 0, 2*3)
 
@@ -291,14 +496,111 @@ func main() {
 			pip.Render(window.GetProjection())
 			gl.Enable(gl.DEPTH_TEST)
 		}
+	}
+
+	if *goldenDir != "" {
+		if !runGolden(*goldenDir, *goldenUpdate, target, fragmentShader, render) {
+			exitCode = 1
+		}
+		return
+	}
+
+	for !w.ShouldClose() {
+		timer.BeginningOfFrame()
+		framerate.BeginningOfFrame(timer.GetTime())
+		frameLength := timer.GetPreviousFrameLength()
+		if player != nil {
+			f, err := player.Step()
+			if err == io.EOF {
+				w.SetShouldClose(true)
+			} else if err != nil {
+				log.Fatalln("failed to replay:", err)
+			}
+			frameLength = f.Length
+		} else {
+			input.Update()
+		}
+		messagebus.Drain()
+		shader.Update()
+		camera.Update(frameLength)
+		if pathAnimation != nil && pathAnimation.Done() {
+			frames, seconds := timer.GetFrameNumber()-pathStartFrame, timer.GetTime()-pathStartTime
+			log.Printf("Path: %d frames in %.3f s - Avg Length: %.3f ms - Avg FPS: %.1f", frames, seconds, seconds*1000/float64(frames), float64(frames)/seconds)
+			pathAnimation = nil
+			if *pathQuit {
+				w.SetShouldClose(true)
+			}
+		}
+		view := camera.Active().GetView()
+		projection := camera.Active().GetProjection()
+
+		render(view, projection)
+		capture.Update()
 
 		// Maintenance
 		w.SwapBuffers()
 		glfw.PollEvents()
-
-		window.RecenterCursor()
 		framerate.EndOfFrame(timer.GetTime())
+		if *frameLimit > 0 && timer.GetFrameNumber() >= *frameLimit {
+			w.SetShouldClose(true)
+		}
+	}
+	capture.StopSequence()
+	capture.Flush()
+}
+
+// runGolden renders every golden scene into target and checks it against its reference image in dir, or replaces the
+// reference if update is true. It returns whether every scene matched.
+func runGolden(dir string, update bool, target *offscreen.Target, fragmentShader *fragmentshader.FragmentShader, render func(view, projection mgl32.Mat4)) bool {
+	pip.Enabled = false
+	c := camera.NewFirstPersonCamera()
+	var results []golden.Result
+	for _, s := range golden.Scenes() {
+		if err := fragmentShader.SetRenderMode(s.RenderMode); err != nil {
+			log.Println(err)
+			results = append(results, golden.Result{Name: s.Name})
+			continue
+		}
+		c.SetViewpoint(s.Viewpoint)
+		render(c.GetView(), c.GetProjection())
+		r, err := golden.Check(dir, s.Name, target.ReadPixels(), update, golden.DefaultOptions)
+		if err != nil {
+			log.Println(err)
+			r.Passed = false
+		}
+		log.Println(r)
+		results = append(results, r)
+	}
+	failed, summary := golden.Summary(results)
+	log.Println(summary)
+	return failed == 0
+}
+
+// material is what a mesh is drawn with, which is its diffuse texture and the fragment shader features it needs.
+type material struct {
+	diffuse  uint32
+	features []string
+}
+
+// allocateDepthMaps sizes each of the provided depth textures to match the window.
+func allocateDepthMaps(textures ...uint32) {
+	for _, t := range textures {
+		gl.BindTexture(gl.TEXTURE_2D, t)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT, int32(window.GetWidth()), int32(window.GetHeight()), 0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// sceneCollider returns the geometry of the grid of cubes and the ground plane, for the camera to collide with.
+func sceneCollider() *camera.Collider {
+	c := &camera.Collider{HasGround: true, GroundHeight: 0}
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			center := mgl32.Vec3{float32(4 * x), 5.0, float32(4 * y)}
+			c.Boxes = append(c.Boxes, camera.AABB{Min: center.Sub(mgl32.Vec3{1, 1, 1}), Max: center.Add(mgl32.Vec3{1, 1, 1})})
+		}
 	}
+	return c
 }
 
 var planeVertices = []vertexshader.Vertex{
@@ -5,16 +5,37 @@ import (
 )
 
 var (
-	typeHandlers = make(map[string][]MessageHandler)
-	mu           sync.Mutex
+	topics = make(map[string]topic)
+	// messageTopics are the topics which Messages are sent on, by type. They are kept apart from topics, so that a
+	// Message type can share its name with a typed Topic, such as "key".
+	messageTopics      = make(map[string]*Topic[*Message])
+	nextSubscriptionID uint64
+	recorder           Recorder
+	mu                 sync.Mutex
 )
 
+// Recorder is notified of every payload published to any Topic, before it is dispatched to handlers.
+type Recorder interface {
+	Record(topic string, payload interface{})
+}
+
+// SetRecorder sets the Recorder which is notified of every published payload. Passing nil stops recording.
+func SetRecorder(r Recorder) {
+	mu.Lock()
+	defer mu.Unlock()
+	recorder = r
+}
+
 // Message is a message being transfered.
 type Message struct {
 	System string
 	Type   string
 	Data1  interface{}
 	Data2  interface{}
+
+	// Consumed may be set by a handler to stop this message from reaching any lower priority handlers. It is cleared
+	// whenever the message is sent, so that a Message can be sent more than once.
+	Consumed bool
 }
 
 // MessageHandler is any function which can be registered to receieve messages.
@@ -22,21 +43,45 @@ type MessageHandler func(m *Message)
 
 // SendSync sends a message syncronously to any listener which is currently Registered to receive it.
 func SendSync(m *Message) {
+	send(messageTopic(m.Type), m)
+}
+
+// SendAsync queues a message to be sent to any listener which is Registered to receive it at the next Drain. It returns
+// false if the message was dropped because the queue is full.
+func SendAsync(m *Message) bool {
+	t := messageTopic(m.Type)
+	return post(func() { send(t, m) })
+}
+
+func send(t *Topic[*Message], m *Message) {
+	m.Consumed = false
+	t.Publish(m)
+}
+
+// messageTopic returns the Topic which Messages of type t are sent on, creating it if this is the first time it has been
+// requested. It is named "message:" followed by t, such as in recordings, so that it can't be confused with a typed
+// Topic of the same name.
+func messageTopic(t string) *Topic[*Message] {
 	mu.Lock()
 	defer mu.Unlock()
-	for _, h := range typeHandlers[m.Type] {
-		h(m)
+	if topic, ok := messageTopics[t]; ok {
+		return topic
 	}
+	topic := &Topic[*Message]{name: "message:" + t}
+	messageTopics[t] = topic
+	return topic
 }
 
-// SendAsync sends a message asyncronously to any listener which is currently Registered to receive it.
-func SendAsync(m *Message) {
-	go SendSync(m)
+// RegisterType registers a function to be called when a message is sent with matching type.
+func RegisterType(t string, h MessageHandler) *Subscription {
+	return RegisterTypeWithPriority(t, PriorityDefault, h)
 }
 
-// RegisterType registers a function to be called when a message is sent with matching type.
-func RegisterType(t string, h MessageHandler) {
-	mu.Lock()
-	defer mu.Unlock()
-	typeHandlers[t] = append(typeHandlers[t], h)
+// RegisterTypeWithPriority registers a function to be called when a message is sent with matching type, and has not
+// been Consumed by a higher priority function.
+func RegisterTypeWithPriority(t string, p Priority, h MessageHandler) *Subscription {
+	return messageTopic(t).SubscribeWithPriority(p, func(m *Message) bool {
+		h(m)
+		return m.Consumed
+	})
 }
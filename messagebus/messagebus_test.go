@@ -0,0 +1,66 @@
+package messagebus
+
+import (
+	"testing"
+)
+
+func TestSendSyncSharesNameWithTypedTopic(t *testing.T) {
+	typed := newTestTopic[int](t)
+	var typedGot []int
+	typed.Subscribe(func(v int) { typedGot = append(typedGot, v) })
+
+	var got []interface{}
+	s := RegisterType(typed.Name(), func(m *Message) { got = append(got, m.Data1) })
+	defer s.Unsubscribe()
+
+	SendSync(&Message{Type: typed.Name(), Data1: "hello"})
+	typed.Publish(1)
+
+	if len(got) != 1 || got[0] != "hello" {
+		t.Errorf("message handler got %v, want [hello]", got)
+	}
+	if len(typedGot) != 1 || typedGot[0] != 1 {
+		t.Errorf("typed handler got %v, want [1]", typedGot)
+	}
+	if s.Topic() == typed.Name() {
+		t.Errorf("message topic and typed topic are both named %q", s.Topic())
+	}
+}
+
+func TestSendSyncResetsConsumed(t *testing.T) {
+	name := newTestTopic[int](t).Name()
+	calls := 0
+	high := RegisterTypeWithPriority(name, PriorityDefault+1, func(m *Message) { m.Consumed = true })
+	low := RegisterType(name, func(m *Message) { calls++ })
+	defer high.Unsubscribe()
+	defer low.Unsubscribe()
+
+	m := &Message{Type: name}
+	SendSync(m)
+	if calls != 0 {
+		t.Fatalf("consumed message reached the lower priority handler")
+	}
+
+	high.Unsubscribe()
+	SendSync(m)
+	if calls != 1 {
+		t.Errorf("resent message reached the lower priority handler %d times, want 1", calls)
+	}
+}
+
+func TestSendAsyncResetsConsumed(t *testing.T) {
+	Drain()
+	name := newTestTopic[int](t).Name()
+	calls := 0
+	s := RegisterType(name, func(m *Message) { calls++ })
+	defer s.Unsubscribe()
+
+	m := &Message{Type: name, Consumed: true}
+	if !SendAsync(m) {
+		t.Fatal("SendAsync dropped the message")
+	}
+	Drain()
+	if calls != 1 {
+		t.Errorf("handler was called %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,94 @@
+package messagebus
+
+import (
+	"sync"
+)
+
+const (
+	// DefaultQueueCapacity is the number of messages the frame queue holds before it starts dropping new ones.
+	DefaultQueueCapacity = 1024
+)
+
+var (
+	queue   = make([]func(), 0, DefaultQueueCapacity)
+	spare   = make([]func(), 0, DefaultQueueCapacity)
+	stats   = QueueStats{Capacity: DefaultQueueCapacity}
+	queueMu sync.Mutex
+)
+
+// QueueStats is a snapshot of the frame queue's counters.
+type QueueStats struct {
+	// Capacity is the maximum number of messages which can be pending at once.
+	Capacity int
+	// Pending is the number of messages waiting for the next Drain.
+	Pending int
+	// HighWater is the largest number of messages that have been pending at once.
+	HighWater int
+	// Posted is the number of messages accepted into the queue.
+	Posted uint64
+	// Delivered is the number of messages which have been dispatched by Drain.
+	Delivered uint64
+	// Dropped is the number of messages rejected because the queue was full.
+	Dropped uint64
+}
+
+// Post queues v to be delivered to this Topic's handlers by the next call to Drain. It is safe to call from any
+// goroutine. Post never blocks; if the queue is full the message is dropped and false is returned, so producers which
+// care can back off and try again next frame.
+func (t *Topic[T]) Post(v T) bool {
+	return post(func() { t.Publish(v) })
+}
+
+func post(f func()) bool {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	if len(queue) >= stats.Capacity {
+		stats.Dropped++
+		return false
+	}
+	queue = append(queue, f)
+	stats.Posted++
+	if len(queue) > stats.HighWater {
+		stats.HighWater = len(queue)
+	}
+	return true
+}
+
+// Drain delivers every message which was queued before it was called, in the order they were posted. It is intended to
+// be called once per frame from the main loop, so that queued handlers always run on the thread which owns the GL
+// context. Messages posted by handlers during a Drain are delivered by the following one.
+func Drain() {
+	queueMu.Lock()
+	pending := queue
+	queue = spare[:0]
+	// Cleared so a Drain nested inside a handler can't hand out the buffer which is being dispatched from.
+	spare = nil
+	queueMu.Unlock()
+
+	for i, f := range pending {
+		f()
+		pending[i] = nil
+	}
+
+	queueMu.Lock()
+	stats.Delivered += uint64(len(pending))
+	spare = pending[:0]
+	queueMu.Unlock()
+}
+
+// SetQueueCapacity changes the number of messages which can be pending at once. Messages already pending are kept even
+// if there are more of them than the new capacity.
+func SetQueueCapacity(n int) {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	stats.Capacity = n
+}
+
+// GetQueueStats returns a snapshot of the frame queue's counters.
+func GetQueueStats() QueueStats {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	s := stats
+	s.Pending = len(queue)
+	return s
+}
@@ -0,0 +1,166 @@
+package messagebus
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+// topic is implemented by every Topic regardless of payload type, so they can share a registry.
+type topic interface {
+	Name() string
+}
+
+// Topic is a named stream of messages which all carry a payload of type T.
+type Topic[T any] struct {
+	name string
+	// subscribers is copy-on-write so that Publish can dispatch from a snapshot without holding mu.
+	subscribers []*subscriber[T]
+}
+
+type subscriber[T any] struct {
+	id       uint64
+	priority Priority
+	handler  func(T) bool
+	removed  atomic.Bool
+}
+
+// Priority orders the handlers on a Topic. Handlers with a higher Priority are called first, and handlers with equal
+// Priority are called in the order they subscribed.
+type Priority int
+
+// The named priorities are the layers which handle input, from the top down. A layer only sees what the layers above it
+// didn't consume.
+const (
+	// PriorityDebug is for debugging tools, such as switching render modes and taking screenshots, which work whatever
+	// else is going on.
+	PriorityDebug Priority = 200
+	// PriorityUI is for anything drawn over the game, such as menus and the window's own controls.
+	PriorityUI Priority = 100
+	// PriorityGame is for the game itself, such as moving the camera.
+	PriorityGame Priority = 0
+
+	// PriorityDefault is the Priority of every handler registered with Subscribe.
+	PriorityDefault = PriorityGame
+)
+
+// Subscription is a handle to a handler which has been subscribed to a Topic.
+type Subscription struct {
+	topic string
+	id    uint64
+	// remove must be called with mu held.
+	remove func(id uint64)
+}
+
+// NewTopic returns the Topic registered under name, creating it if this is the first time it has been requested.
+// Requesting a name which is already registered with a different payload type panics, since messages on it could never be delivered.
+func NewTopic[T any](name string) *Topic[T] {
+	mu.Lock()
+	defer mu.Unlock()
+	if existing, ok := topics[name]; ok {
+		t, ok := existing.(*Topic[T])
+		if !ok {
+			panic(fmt.Sprintf("messagebus: topic %q is already registered as %T", name, existing))
+		}
+		return t
+	}
+	t := &Topic[T]{name: name}
+	topics[name] = t
+	return t
+}
+
+// Name returns the name this Topic was registered under.
+func (t *Topic[T]) Name() string {
+	return t.name
+}
+
+// Subscribe registers h to be called with every payload published to this Topic at PriorityDefault.
+func (t *Topic[T]) Subscribe(h func(T)) *Subscription {
+	return t.SubscribeWithPriority(PriorityDefault, func(v T) bool {
+		h(v)
+		return false
+	})
+}
+
+// SubscribeWithPriority registers h to be called with every payload published to this Topic which has not already been
+// consumed by a higher priority handler. If h returns true the payload is consumed, and no lower priority handler will
+// see it.
+func (t *Topic[T]) SubscribeWithPriority(p Priority, h func(T) bool) *Subscription {
+	mu.Lock()
+	defer mu.Unlock()
+	nextSubscriptionID++
+	s := &subscriber[T]{id: nextSubscriptionID, priority: p, handler: h}
+
+	i := 0
+	for i < len(t.subscribers) && t.subscribers[i].priority >= p {
+		i++
+	}
+	subscribers := make([]*subscriber[T], 0, len(t.subscribers)+1)
+	subscribers = append(subscribers, t.subscribers[:i]...)
+	subscribers = append(subscribers, s)
+	t.subscribers = append(subscribers, t.subscribers[i:]...)
+
+	return &Subscription{topic: t.name, id: s.id, remove: t.remove}
+}
+
+// Publish synchronously delivers v to every handler currently subscribed to this Topic, in priority order, until one of
+// them consumes it.
+//
+// No lock is held while handlers run, so handlers may freely Publish, Subscribe and Unsubscribe. A Publish from inside
+// a handler is nested: it is delivered in full before the outer dispatch moves on to its next handler. Handlers
+// subscribed during a dispatch first receive the next Publish, while handlers unsubscribed during a dispatch are not
+// called again, even by the dispatch already in progress.
+func (t *Topic[T]) Publish(v T) {
+	mu.Lock()
+	subscribers := t.subscribers
+	r := recorder
+	mu.Unlock()
+	if r != nil {
+		r.Record(t.name, v)
+	}
+	for _, s := range subscribers {
+		if s.removed.Load() {
+			continue
+		}
+		if s.call(t.name, v) {
+			return
+		}
+	}
+}
+
+func (t *Topic[T]) remove(id uint64) {
+	for i, s := range t.subscribers {
+		if s.id == id {
+			s.removed.Store(true)
+			t.subscribers = append(t.subscribers[:i:i], t.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// call invokes the handler and reports whether it consumed v, isolating the remaining subscribers from a panic inside it.
+func (s *subscriber[T]) call(name string, v T) (consumed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("messagebus: handler %d on topic %q panicked: %v", s.id, name, r)
+			consumed = false
+		}
+	}()
+	return s.handler(v)
+}
+
+// Unsubscribe removes this handler from its Topic. Calling it more than once has no effect.
+func (s *Subscription) Unsubscribe() {
+	mu.Lock()
+	defer mu.Unlock()
+	if s.remove == nil {
+		return
+	}
+	s.remove(s.id)
+	s.remove = nil
+}
+
+// Topic returns the name of the Topic this Subscription belongs to.
+func (s *Subscription) Topic() string {
+	return s.topic
+}
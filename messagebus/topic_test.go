@@ -0,0 +1,294 @@
+package messagebus
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+var testTopics int
+
+// newTestTopic returns a Topic which no other test, or earlier run of the same test, has subscribed to.
+func newTestTopic[T any](t *testing.T) *Topic[T] {
+	testTopics++
+	return NewTopic[T](fmt.Sprintf("%s/%d", t.Name(), testTopics))
+}
+
+func TestNestedPublishIsDeliveredBeforeTheNextHandler(t *testing.T) {
+	topic := newTestTopic[string](t)
+	var got []string
+	topic.Subscribe(func(v string) {
+		got = append(got, "first "+v)
+		if v == "outer" {
+			topic.Publish("inner")
+		}
+	})
+	topic.Subscribe(func(v string) {
+		got = append(got, "second "+v)
+	})
+
+	topic.Publish("outer")
+
+	want := []string{"first outer", "first inner", "second inner", "second outer"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSubscribeDuringDispatchStartsAtTheNextPublish(t *testing.T) {
+	topic := newTestTopic[int](t)
+	var got []string
+	subscribed := false
+	topic.Subscribe(func(v int) {
+		got = append(got, fmt.Sprintf("first %d", v))
+		if !subscribed {
+			subscribed = true
+			topic.Subscribe(func(v int) {
+				got = append(got, fmt.Sprintf("late %d", v))
+			})
+		}
+	})
+
+	topic.Publish(1)
+	topic.Publish(2)
+
+	want := []string{"first 1", "first 2", "late 2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestUnsubscribeDuringDispatchSkipsTheRemovedHandler(t *testing.T) {
+	topic := newTestTopic[int](t)
+	var got []string
+	var second *Subscription
+	topic.Subscribe(func(v int) {
+		got = append(got, fmt.Sprintf("first %d", v))
+		second.Unsubscribe()
+	})
+	second = topic.Subscribe(func(v int) {
+		got = append(got, fmt.Sprintf("second %d", v))
+	})
+	topic.Subscribe(func(v int) {
+		got = append(got, fmt.Sprintf("third %d", v))
+	})
+
+	topic.Publish(1)
+	topic.Publish(2)
+
+	want := []string{"first 1", "third 1", "first 2", "third 2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestUnsubscribeSelfDuringDispatch(t *testing.T) {
+	topic := newTestTopic[int](t)
+	calls := 0
+	var s *Subscription
+	s = topic.Subscribe(func(v int) {
+		calls++
+		s.Unsubscribe()
+		s.Unsubscribe()
+	})
+
+	topic.Publish(1)
+	topic.Publish(2)
+
+	if calls != 1 {
+		t.Errorf("handler was called %d times, want 1", calls)
+	}
+}
+
+func TestPanickingHandlerDoesNotStopTheOthers(t *testing.T) {
+	topic := newTestTopic[int](t)
+	var got []int
+	topic.Subscribe(func(v int) { got = append(got, v) })
+	topic.Subscribe(func(v int) { panic("bad handler") })
+	topic.Subscribe(func(v int) { got = append(got, v*10) })
+
+	topic.Publish(1)
+	topic.Publish(2)
+
+	want := []int{1, 10, 2, 20}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestNewTopicReturnsTheRegisteredTopic(t *testing.T) {
+	if a, b := NewTopic[int]("registered"), NewTopic[int]("registered"); a != b {
+		t.Errorf("NewTopic returned two different topics for the same name")
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	topic := newTestTopic[int](t)
+	var got []string
+	add := func(name string, p Priority) {
+		topic.SubscribeWithPriority(p, func(int) bool {
+			got = append(got, name)
+			return false
+		})
+	}
+	add("default", PriorityDefault)
+	add("high", PriorityDefault+10)
+	add("low", PriorityDefault-10)
+	add("default again", PriorityDefault)
+	add("middle", PriorityDefault+5)
+
+	topic.Publish(0)
+
+	want := []string{"high", "middle", "default", "default again", "low"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestConsumedStopsDispatch(t *testing.T) {
+	topic := newTestTopic[int](t)
+	var got []string
+	topic.Subscribe(func(int) { got = append(got, "low") })
+	topic.SubscribeWithPriority(PriorityDefault+1, func(v int) bool {
+		got = append(got, fmt.Sprintf("high %d", v))
+		return v == 1
+	})
+
+	topic.Publish(1)
+	topic.Publish(2)
+
+	want := []string{"high 1", "high 2", "low"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// resetQueue drains anything left over from other tests, and restores the capacity and counters once the test is done.
+func resetQueue(t *testing.T) {
+	Drain()
+	queueMu.Lock()
+	saved := stats
+	stats = QueueStats{Capacity: saved.Capacity}
+	queueMu.Unlock()
+	t.Cleanup(func() {
+		Drain()
+		queueMu.Lock()
+		stats = saved
+		queueMu.Unlock()
+	})
+}
+
+func TestPostIsDeliveredInOrderByDrain(t *testing.T) {
+	resetQueue(t)
+	a := newTestTopic[int](t)
+	b := newTestTopic[string](t)
+	var got []string
+	a.Subscribe(func(v int) { got = append(got, fmt.Sprintf("a %d", v)) })
+	b.Subscribe(func(v string) { got = append(got, "b "+v) })
+
+	a.Post(1)
+	b.Post("x")
+	a.Post(2)
+	if len(got) != 0 {
+		t.Fatalf("Post delivered %v before Drain", got)
+	}
+
+	Drain()
+
+	want := []string{"a 1", "b x", "a 2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPostDuringDrainIsDeliveredByTheNextDrain(t *testing.T) {
+	resetQueue(t)
+	topic := newTestTopic[int](t)
+	var got []int
+	topic.Subscribe(func(v int) {
+		got = append(got, v)
+		if v == 1 {
+			topic.Post(2)
+		}
+	})
+
+	topic.Post(1)
+	Drain()
+	if !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("after the first Drain got %v, want [1]", got)
+	}
+	Drain()
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("after the second Drain got %v, want [1 2]", got)
+	}
+}
+
+func TestPostDropsAtCapacity(t *testing.T) {
+	resetQueue(t)
+	SetQueueCapacity(3)
+	defer SetQueueCapacity(DefaultQueueCapacity)
+	topic := newTestTopic[int](t)
+	var got []int
+	topic.Subscribe(func(v int) { got = append(got, v) })
+
+	for i := 1; i <= 5; i++ {
+		accepted := topic.Post(i)
+		if want := i <= 3; accepted != want {
+			t.Errorf("Post(%d) returned %v, want %v", i, accepted, want)
+		}
+	}
+	Drain()
+
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestQueueStats(t *testing.T) {
+	resetQueue(t)
+	SetQueueCapacity(2)
+	defer SetQueueCapacity(DefaultQueueCapacity)
+	topic := newTestTopic[int](t)
+
+	topic.Post(1)
+	topic.Post(2)
+	topic.Post(3)
+	got := GetQueueStats()
+	want := QueueStats{Capacity: 2, Pending: 2, HighWater: 2, Posted: 2, Dropped: 1}
+	if got != want {
+		t.Errorf("before Drain got %+v, want %+v", got, want)
+	}
+
+	Drain()
+	topic.Post(4)
+	got = GetQueueStats()
+	want = QueueStats{Capacity: 2, Pending: 1, HighWater: 2, Posted: 3, Delivered: 2, Dropped: 1}
+	if got != want {
+		t.Errorf("after Drain got %+v, want %+v", got, want)
+	}
+}
+
+func TestPostFromManyGoroutines(t *testing.T) {
+	resetQueue(t)
+	topic := newTestTopic[int](t)
+	got := 0
+	topic.Subscribe(func(int) { got++ })
+
+	var wg sync.WaitGroup
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				topic.Post(i)
+			}
+		}()
+	}
+	wg.Wait()
+	Drain()
+
+	if got != 500 {
+		t.Errorf("got %d messages, want 500", got)
+	}
+}
@@ -0,0 +1,105 @@
+package offscreen
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+// Target is a framebuffer with a colour texture and a depth buffer, which can be rendered into in place of the window,
+// such as when there is no display to show it on.
+type Target struct {
+	framebuffer uint32
+	color       uint32
+	depth       uint32
+	width       uint32
+	height      uint32
+}
+
+// NewTarget builds a Target of the provided size.
+func NewTarget(width, height uint32) (*Target, error) {
+	t := &Target{}
+	gl.GenFramebuffers(1, &t.framebuffer)
+	gl.GenTextures(1, &t.color)
+	gl.GenRenderbuffers(1, &t.depth)
+
+	gl.BindTexture(gl.TEXTURE_2D, t.color)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	t.Resize(width, height)
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffer)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, t.color, 0)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, t.depth)
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		t.Delete()
+		return nil, fmt.Errorf("failed to build offscreen framebuffer: status 0x%x", status)
+	}
+	return t, nil
+}
+
+// Resize reallocates this Target's attachments at the provided size, discarding whatever was rendered into them.
+func (t *Target) Resize(width, height uint32) {
+	t.width, t.height = width, height
+
+	gl.BindTexture(gl.TEXTURE_2D, t.color)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	gl.BindRenderbuffer(gl.RENDERBUFFER, t.depth)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+}
+
+// GetFramebuffer returns the framebuffer to bind to render into this Target.
+func (t *Target) GetFramebuffer() uint32 {
+	return t.framebuffer
+}
+
+// GetColorTexture returns the texture holding whatever has been rendered into this Target.
+func (t *Target) GetColorTexture() uint32 {
+	return t.color
+}
+
+// GetSize returns the width and height of this Target, in pixels.
+func (t *Target) GetSize() (uint32, uint32) {
+	return t.width, t.height
+}
+
+// ReadPixels copies whatever has been rendered into this Target back from the GPU, waiting for rendering to finish.
+func (t *Target) ReadPixels() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, int(t.width), int(t.height)))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, t.framebuffer)
+	gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(0, 0, int32(t.width), int32(t.height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	FlipRows(img)
+	return img
+}
+
+// FlipRows turns img upside down in place, which converts between OpenGL's bottom to top rows and an image's top to
+// bottom rows.
+func FlipRows(img *image.RGBA) {
+	row := make([]uint8, img.Stride)
+	for top, bottom := 0, img.Rect.Dy()-1; top < bottom; top, bottom = top+1, bottom-1 {
+		t := img.Pix[top*img.Stride : (top+1)*img.Stride]
+		b := img.Pix[bottom*img.Stride : (bottom+1)*img.Stride]
+		copy(row, t)
+		copy(t, b)
+		copy(b, row)
+	}
+}
+
+// Delete frees this Target's framebuffer and attachments, after which it can't be used.
+func (t *Target) Delete() {
+	gl.DeleteFramebuffers(1, &t.framebuffer)
+	gl.DeleteTextures(1, &t.color)
+	gl.DeleteRenderbuffers(1, &t.depth)
+}
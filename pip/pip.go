@@ -1,15 +1,14 @@
 package pip
 
 import (
-	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/brandonnelson3/GameEngine/input"
 	"github.com/brandonnelson3/GameEngine/window"
 	"github.com/go-gl/gl/v4.5-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
 var (
-	pipeline, planeVao uint32
+	pipeline, planeVao, planeVbo uint32
 
 	vertexShader   *VertexShader
 	fragmentShader *FragmentShader
@@ -38,15 +37,49 @@ func Initialize(m *uint32) {
 	gl.UseProgram(0)
 	gl.BindProgramPipeline(pipeline)
 
-	sizex := float32(480.0)
-	sizey := float32(360.0)
+	gl.GenVertexArrays(1, &planeVao)
+	gl.BindVertexArray(planeVao)
 
-	padding := uint32(50)
+	gl.GenBuffers(1, &planeVbo)
+	layout()
 
-	topLeft := mgl32.Vec2{float32(window.Width-padding) - sizex, float32(window.Height-padding) - sizey}
-	topRight := mgl32.Vec2{float32(window.Width - padding), float32(window.Height-padding) - sizey}
-	botLeft := mgl32.Vec2{float32(window.Width-padding) - sizex, float32(window.Height - padding)}
-	botRight := mgl32.Vec2{float32(window.Width - padding), float32(window.Height - padding)}
+	vertexShader.BindVertexAttributes()
+
+	window.ResizeTopic.Subscribe(func(window.Resize) {
+		layout()
+	})
+
+	input.ActionTopic.Subscribe(func(a input.ActionInput) {
+		if a.WasPressed("ShowDepthPip") {
+			Enabled = true
+		}
+		if a.WasPressed("HideDepthPip") {
+			Enabled = false
+		}
+	})
+}
+
+// layout places the pip in the bottom right corner of the window, at the window's content scale, shrinking it if the
+// window is too small for it.
+func layout() {
+	scale := window.GetContentScale()
+	padding := 50 * scale
+	if s := (float32(window.GetWidth()) - 2*padding) / 480; s < scale {
+		scale = s
+	}
+	if s := (float32(window.GetHeight()) - 2*padding) / 360; s < scale {
+		scale = s
+	}
+	if scale < 0 {
+		scale = 0
+	}
+	sizex := 480 * scale
+	sizey := 360 * scale
+
+	topLeft := mgl32.Vec2{float32(window.GetWidth()) - padding - sizex, float32(window.GetHeight()) - padding - sizey}
+	topRight := mgl32.Vec2{float32(window.GetWidth()) - padding, float32(window.GetHeight()) - padding - sizey}
+	botLeft := mgl32.Vec2{float32(window.GetWidth()) - padding - sizex, float32(window.GetHeight()) - padding}
+	botRight := mgl32.Vec2{float32(window.GetWidth()) - padding, float32(window.GetHeight()) - padding}
 
 	planeVertices := []Vertex{
 		{topLeft, mgl32.Vec2{0, 1}},
@@ -57,32 +90,13 @@ func Initialize(m *uint32) {
 		{botLeft, mgl32.Vec2{0, 0}},
 	}
 
-	gl.GenVertexArrays(1, &planeVao)
-	gl.BindVertexArray(planeVao)
-
-	var planeVbo uint32
-	gl.GenBuffers(1, &planeVbo)
 	gl.BindBuffer(gl.ARRAY_BUFFER, planeVbo)
 	gl.BufferData(gl.ARRAY_BUFFER, len(planeVertices)*4*4, gl.Ptr(planeVertices), gl.STATIC_DRAW)
-
-	vertexShader.BindVertexAttributes()
-
-	messagebus.RegisterType("key", func(m *messagebus.Message) {
-		pressedKeys := m.Data1.([]glfw.Key)
-		for _, key := range pressedKeys {
-			switch key {
-			case glfw.KeyPageUp:
-				Enabled = true
-			case glfw.KeyPageDown:
-				Enabled = false
-			}
-		}
-	})
 }
 
 func Render(p mgl32.Mat4) {
 	gl.BindProgramPipeline(pipeline)
-	vertexShader.Projection.Set(mgl32.Ortho(0.0, float32(window.Width), float32(window.Height), 0.0, -1.0, 1.0))
+	vertexShader.Projection.Set(mgl32.Ortho(0.0, float32(window.GetWidth()), float32(window.GetHeight()), 0.0, -1.0, 1.0))
 	// This is intentionally different since it needs to be the projection matrix that the depthMap was rendered with.
 	fragmentShader.Projection.Set(p)
 	fragmentShader.DepthMap.Set(gl.TEXTURE4, 4, *DepthMap)
@@ -0,0 +1,19 @@
+#version 450
+
+uniform sampler2D textureSampler;
+uniform mat4 projection;
+
+in VERTEX_OUT
+{
+	vec2 uv;
+} fragment_in;
+
+out vec4 outputColor;
+
+void main() {
+	float depth = texture(textureSampler, fragment_in.uv).r;
+	// Linearize the depth value from depth buffer (must do this because we created it using projection)
+	depth = 1 - 1/log((0.5 * projection[3][2]) / (depth + 0.5 * projection[2][2] - 0.5));
+
+	outputColor = vec4(vec3(depth), 1.0);
+}
@@ -0,0 +1,21 @@
+#version 450
+
+in vec2 pos;
+in vec2 uv;
+
+uniform mat4 projection;
+
+out gl_PerVertex
+{
+    vec4 gl_Position;
+};
+
+out VERTEX_OUT
+{
+	vec2 uv;
+} vertex_out;
+
+void main() {
+    gl_Position = projection * vec4(pos, 0, 1);
+	vertex_out.uv = uv;
+}
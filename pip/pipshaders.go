@@ -1,62 +1,17 @@
 package pip
 
 import (
-	"fmt"
-	"strings"
-
+	"github.com/brandonnelson3/GameEngine/shader"
 	"github.com/brandonnelson3/GameEngine/uniforms"
 	"github.com/go-gl/gl/v4.5-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
-const (
-	originalSourceFile = `pipshader.`
-	fragSrc            = `
-#version 450
-
-uniform sampler2D textureSampler;
-uniform mat4 projection;
-
-in VERTEX_OUT
-{
-    vec4 gl_Position;
-	vec2 uv;
-} fragment_in;
-
-out vec4 outputColor;
-
-void main() {
-	float depth = texture(textureSampler, fragment_in.uv).r;
-	// Linearize the depth value from depth buffer (must do this because we created it using projection)
-	depth = 1 - 1/log((0.5 * projection[3][2]) / (depth + 0.5 * projection[2][2] - 0.5));
-
-	outputColor = vec4(vec3(depth), 1.0);
-}` + "\x00"
-	vertSrc = `
-#version 450
-
-in vec2 pos;
-in vec2 uv;
-
-uniform mat4 projection;
-
-out gl_PerVertex
-{
-    vec4 gl_Position;
-	vec2 uv;
-} vertex_out;
-
-void main() {
-    gl_Position = projection * vec4(pos, 0, 1);
-	vertex_out.uv = uv;
-}` + "\x00"
-)
-
 // FragmentShader represents a FragmentShader
 type FragmentShader struct {
 	uint32
 
-	DepthMap   *uniforms.Sampler2D
+	DepthMap   *uniforms.Sampler2D `uniform:"textureSampler"`
 	Projection *uniforms.Matrix4
 }
 
@@ -74,100 +29,33 @@ type VertexShader struct {
 
 // NewFragmentShader instantiates and initializes a PipFragmentShader object.
 func NewFragmentShader() (*FragmentShader, error) {
-	program := gl.CreateProgram()
-	shader := gl.CreateShader(gl.FRAGMENT_SHADER)
-
-	csources, free := gl.Strs(fragSrc)
-	gl.ShaderSource(shader, 1, csources, nil)
-	free()
-	gl.CompileShader(shader)
-
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
-
-		return nil, fmt.Errorf("failed to compile %v: %v", originalSourceFile+"frag", log)
+	p, err := shader.Load("pip/pipshader.frag")
+	if err != nil {
+		return nil, err
 	}
 
-	gl.AttachShader(program, shader)
-	gl.ProgramParameteri(program, gl.PROGRAM_SEPARABLE, gl.TRUE)
-	gl.LinkProgram(program)
-
-	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+	gl.BindFragDataLocation(p.ID(), 0, gl.Str("outputColor\x00"))
 
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
-
-		return nil, fmt.Errorf("failed to link %v: %v", originalSourceFile+"frag", log)
+	s := &FragmentShader{uint32: p.ID()}
+	if err := p.Populate(s); err != nil {
+		return nil, err
 	}
-
-	depthMapLoc := gl.GetUniformLocation(program, gl.Str("textureSampler\x00"))
-	projectionLoc := gl.GetUniformLocation(program, gl.Str("projection\x00"))
-
-	gl.DeleteShader(shader)
-
-	gl.BindFragDataLocation(program, 0, gl.Str("outputColor\x00"))
-
-	return &FragmentShader{
-		uint32:     program,
-		DepthMap:   uniforms.NewSampler2D(program, depthMapLoc),
-		Projection: uniforms.NewMatrix4(program, projectionLoc),
-	}, nil
+	p.OnReload(func() error { return p.Populate(s) })
+	return s, nil
 }
 
 // NewVertexShader instantiates and initializes a shader object.
 func NewVertexShader() (*VertexShader, error) {
-	program := gl.CreateProgram()
-	shader := gl.CreateShader(gl.VERTEX_SHADER)
-
-	csources, free := gl.Strs(vertSrc)
-	gl.ShaderSource(shader, 1, csources, nil)
-	free()
-	gl.CompileShader(shader)
-
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
-
-		return nil, fmt.Errorf("failed to compile %v: %v", originalSourceFile+"vert", log)
+	p, err := shader.Load("pip/pipshader.vert")
+	if err != nil {
+		return nil, err
 	}
-
-	gl.AttachShader(program, shader)
-	gl.ProgramParameteri(program, gl.PROGRAM_SEPARABLE, gl.TRUE)
-	gl.LinkProgram(program)
-
-	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
-
-		return nil, fmt.Errorf("failed to link %v: %v", originalSourceFile+"vert", log)
+	s := &VertexShader{uint32: p.ID()}
+	if err := p.Populate(s); err != nil {
+		return nil, err
 	}
-
-	projectionLoc := gl.GetUniformLocation(program, gl.Str("projection\x00"))
-
-	gl.DeleteShader(shader)
-
-	return &VertexShader{
-		uint32:     program,
-		Projection: uniforms.NewMatrix4(program, projectionLoc),
-	}, nil
+	p.OnReload(func() error { return p.Populate(s) })
+	return s, nil
 }
 
 // BindVertexAttributes binds the attributes per vertex.
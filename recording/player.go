@@ -0,0 +1,99 @@
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/brandonnelson3/GameEngine/timer"
+)
+
+var (
+	replayers = make(map[string]func(payload json.RawMessage) error)
+	mu        sync.Mutex
+)
+
+// Register marks the provided Topic as replayable, so that a Player will publish any messages which were recorded on it.
+// Only topics which drive the engine from outside, such as input, should be registered; anything derived from them is
+// reproduced by the engine itself during replay.
+func Register[T any](t *messagebus.Topic[T]) {
+	mu.Lock()
+	defer mu.Unlock()
+	replayers[t.Name()] = func(payload json.RawMessage) error {
+		var v T
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return err
+		}
+		t.Publish(v)
+		return nil
+	}
+}
+
+// Player replays a recording written by a Recorder, one frame at a time.
+type Player struct {
+	dec  *json.Decoder
+	next *entry
+}
+
+// NewPlayer instantiates a Player which reads a recording from r.
+func NewPlayer(r io.Reader) *Player {
+	return &Player{dec: json.NewDecoder(r)}
+}
+
+// Step publishes every replayable message which was recorded during the next frame, and returns that frame so that the
+// caller can advance the simulation by exactly the recorded frame length. It returns io.EOF once the recording has been
+// exhausted.
+func (p *Player) Step() (timer.Frame, error) {
+	var f timer.Frame
+	haveFrame, replayed := false, false
+	for {
+		e, err := p.peek()
+		if err == io.EOF && (haveFrame || replayed) {
+			return f, nil
+		}
+		if err != nil {
+			return f, err
+		}
+		if e.Topic == timer.FrameTopic.Name() {
+			if haveFrame {
+				// This is the beginning of the following frame, leave it for the next Step.
+				return f, nil
+			}
+			if err := json.Unmarshal(e.Payload, &f); err != nil {
+				return f, fmt.Errorf("failed to decode frame %d: %v", e.Frame, err)
+			}
+			haveFrame = true
+			p.next = nil
+			continue
+		}
+		p.next = nil
+		if err := replay(e); err != nil {
+			return f, fmt.Errorf("failed to replay %q message in frame %d: %v", e.Topic, e.Frame, err)
+		}
+		replayed = true
+	}
+}
+
+func (p *Player) peek() (*entry, error) {
+	if p.next != nil {
+		return p.next, nil
+	}
+	e := &entry{}
+	if err := p.dec.Decode(e); err != nil {
+		return nil, err
+	}
+	p.next = e
+	return e, nil
+}
+
+func replay(e *entry) error {
+	mu.Lock()
+	r, ok := replayers[e.Topic]
+	mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return r(e.Payload)
+}
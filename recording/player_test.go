@@ -0,0 +1,130 @@
+package recording
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/brandonnelson3/GameEngine/input"
+	"github.com/brandonnelson3/GameEngine/timer"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+func TestReplayPublishesEachFrame(t *testing.T) {
+	Register(input.ActionTopic)
+	Register(input.SnapshotTopic)
+
+	type published struct {
+		actions   []input.ActionInput
+		snapshots []input.Snapshot
+	}
+	var frame published
+	actions := input.ActionTopic.Subscribe(func(a input.ActionInput) {
+		frame.actions = append(frame.actions, input.ActionInput{
+			Held:              a.Held,
+			PressedThisFrame:  a.PressedThisFrame,
+			ReleasedThisFrame: a.ReleasedThisFrame,
+			Axes:              a.Axes,
+		})
+	})
+	defer actions.Unsubscribe()
+	snapshots := input.SnapshotTopic.Subscribe(func(s input.Snapshot) {
+		frame.snapshots = append(frame.snapshots, s)
+	})
+	defer snapshots.Unsubscribe()
+
+	f, err := os.Open("testdata/walk.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	player := NewPlayer(f)
+
+	// walk.jsonl was recorded while W was held, Sprint was pressed with Left Shift, and P was tapped to save a bookmark.
+	// The times are exactly as recorded.
+	w, shift, p := input.Control(glfw.KeyW), input.Control(glfw.KeyLeftShift), input.Control(glfw.KeyP)
+	want := []struct {
+		frame timer.Frame
+		published
+	}{
+		{
+			timer.Frame{Number: 1, Length: 0.5},
+			published{nil, []input.Snapshot{{Time: 0.5}}},
+		},
+		{
+			timer.Frame{Number: 2, Length: 0.016000000000000014},
+			published{
+				[]input.ActionInput{{Axes: map[string]float32{"MoveForward": 1}}},
+				[]input.Snapshot{{Time: 0.516, Controls: []input.ControlState{
+					{Control: w, Down: true, Pressed: true, HeldFor: 0.006000000000000005},
+				}}},
+			},
+		},
+		{
+			timer.Frame{Number: 3, Length: 0.016000000000000014},
+			published{
+				[]input.ActionInput{{
+					Held:             []string{"Sprint"},
+					PressedThisFrame: []string{"Sprint"},
+					Axes:             map[string]float32{"MoveForward": 1},
+				}},
+				[]input.Snapshot{{Time: 0.532, Mods: glfw.ModShift, Controls: []input.ControlState{
+					{Control: w, Down: true, HeldFor: 0.02200000000000002},
+					{Control: shift, Down: true, Pressed: true, HeldFor: 0.01200000000000001},
+				}}},
+			},
+		},
+		{
+			timer.Frame{Number: 4, Length: 0.016000000000000014},
+			published{
+				[]input.ActionInput{{
+					Held:              []string{"Sprint"},
+					PressedThisFrame:  []string{"SaveBookmark"},
+					ReleasedThisFrame: []string{"SaveBookmark"},
+					Axes:              map[string]float32{"MoveForward": 1},
+				}},
+				// P went down and up again within the frame, so it was never held.
+				[]input.Snapshot{{Time: 0.548, Mods: glfw.ModShift, Controls: []input.ControlState{
+					{Control: p, Pressed: true, Released: true},
+					{Control: w, Down: true, HeldFor: 0.038000000000000034},
+					{Control: shift, Down: true, HeldFor: 0.028000000000000025},
+				}}},
+			},
+		},
+		{
+			timer.Frame{Number: 5, Length: 0.016999999999999904},
+			published{
+				[]input.ActionInput{{ReleasedThisFrame: []string{"Sprint"}, Axes: map[string]float32{}}},
+				[]input.Snapshot{{Time: 0.565, Controls: []input.ControlState{
+					{Control: w, Released: true},
+					{Control: shift, Released: true},
+				}}},
+			},
+		},
+		{
+			timer.Frame{Number: 6, Length: 0.016000000000000014},
+			published{nil, []input.Snapshot{{Time: 0.581}}},
+		},
+	}
+
+	for _, step := range want {
+		frame = published{}
+		got, err := player.Step()
+		if err != nil {
+			t.Fatalf("failed to step to frame %d: %v", step.frame.Number, err)
+		}
+		if got != step.frame {
+			t.Errorf("got frame %+v, want %+v", got, step.frame)
+		}
+		if !reflect.DeepEqual(frame.actions, step.actions) {
+			t.Errorf("frame %d replayed actions %+v, want %+v", step.frame.Number, frame.actions, step.actions)
+		}
+		if !reflect.DeepEqual(frame.snapshots, step.snapshots) {
+			t.Errorf("frame %d replayed snapshots %+v, want %+v", step.frame.Number, frame.snapshots, step.snapshots)
+		}
+	}
+	if _, err := player.Step(); err != io.EOF {
+		t.Errorf("stepping past the last frame returned %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,69 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/brandonnelson3/GameEngine/timer"
+)
+
+// entry is a single line of a recording.
+type entry struct {
+	Frame   uint64          `json:"frame"`
+	Topic   string          `json:"topic"`
+	System  string          `json:"system,omitempty"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Recorder writes every message published on the messagebus to a JSON-lines stream.
+type Recorder struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+// Start begins recording every message published on the messagebus to w, until Stop is called.
+func Start(w io.Writer) *Recorder {
+	bw := bufio.NewWriter(w)
+	r := &Recorder{w: bw, enc: json.NewEncoder(bw)}
+	messagebus.SetRecorder(r)
+	return r
+}
+
+// Record writes a single message to the recording. It is called by the messagebus for every published payload.
+func (r *Recorder) Record(topic string, payload interface{}) {
+	e := entry{Frame: timer.GetFrameNumber(), Topic: topic}
+	if m, ok := payload.(*messagebus.Message); ok {
+		e.System = m.System
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		// Keep something readable in the recording, even though it can't be replayed.
+		raw, _ = json.Marshal(fmt.Sprintf("%v", payload))
+	}
+	e.Payload = raw
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(e)
+}
+
+// Stop stops recording and flushes the recording, returning the first error encountered while writing it.
+func (r *Recorder) Stop() error {
+	messagebus.SetRecorder(nil)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.err = r.w.Flush()
+	return r.err
+}
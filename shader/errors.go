@@ -0,0 +1,107 @@
+package shader
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// contextLines is how many lines either side of an error are shown with it.
+const contextLines = 2
+
+// logLine matches a line of a compile log which refers to a line of the source, starting with the number of the source
+// string the line is in. Drivers write these differently, such as "0(12) : error C0000: ..." on NVIDIA,
+// "0:12(5): error: ..." on Mesa and "ERROR: 0:12: ..." on AMD and Intel.
+var logLine = regexp.MustCompile(`^(?:(ERROR|WARNING):\s*)?(\d+)[:(](\d+)\)?(?:\(\d+\))?\s*:\s*(.*)$`)
+
+// CompileError is a shader stage which failed to compile.
+type CompileError struct {
+	// Name is the file the stage's source came from.
+	Name string
+	// Lines are the errors and warnings which refer to a line of the source, in the order they were logged.
+	Lines []LineError
+	// Log is the driver's compile log, as it was written.
+	Log string
+}
+
+// LineError is an error or warning on a line of a shader's source.
+type LineError struct {
+	// File is the file the line is in, which is the stage's own unless the line was included from another.
+	File string
+	// Line is the line number, starting from 1.
+	Line    int
+	Message string
+	// Context is the source around Line, with each line prefixed by its number and the erroneous line marked.
+	Context []string
+}
+
+// newCompileError makes a CompileError from a compile log, where sources are the files which went into the stage by
+// source string number.
+func newCompileError(sources []source, log string) *CompileError {
+	e := &CompileError{Name: sources[0].name, Log: log}
+	for _, l := range strings.Split(log, "\n") {
+		m := logLine.FindStringSubmatch(strings.TrimSpace(l))
+		if m == nil {
+			continue
+		}
+		number, err := strconv.Atoi(m[2])
+		if err != nil || number >= len(sources) {
+			continue
+		}
+		line, err := strconv.Atoi(m[3])
+		if err != nil {
+			continue
+		}
+		message := m[4]
+		if m[1] != "" {
+			message = strings.ToLower(m[1]) + ": " + message
+		}
+		s := sources[number]
+		e.Lines = append(e.Lines, LineError{File: s.name, Line: line, Message: message, Context: context(strings.Split(s.text, "\n"), line)})
+	}
+	return e
+}
+
+// context returns the lines of source around line, numbered, with line itself marked.
+func context(source []string, line int) []string {
+	var c []string
+	for i := line - contextLines; i <= line+contextLines; i++ {
+		if i < 1 || i > len(source) {
+			continue
+		}
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		c = append(c, fmt.Sprintf("%s %4d | %s", marker, i, strings.TrimRight(source[i-1], "\r")))
+	}
+	return c
+}
+
+// Error returns every error with its source context, or the whole log if none of it could be matched to the source.
+func (e *CompileError) Error() string {
+	if len(e.Lines) == 0 {
+		return fmt.Sprintf("failed to compile %v: %v", e.Name, e.Log)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to compile %v:", e.Name)
+	for _, l := range e.Lines {
+		fmt.Fprintf(&b, "\n%s:%d: %s", l.File, l.Line, l.Message)
+		for _, c := range l.Context {
+			fmt.Fprintf(&b, "\n\t%s", c)
+		}
+	}
+	return b.String()
+}
+
+// LinkError is a program which failed to link.
+type LinkError struct {
+	// Name is the names of the program's stages.
+	Name string
+	Log  string
+}
+
+func (e *LinkError) Error() string {
+	return fmt.Sprintf("failed to link %v: %v", e.Name, e.Log)
+}
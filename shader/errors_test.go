@@ -0,0 +1,117 @@
+package shader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileErrorParsesEachDriversLog(t *testing.T) {
+	sources := []source{{"shader.frag", "#version 450\n\nvoid main() {\n\tfoo = 1.0;\n}"}}
+
+	for _, c := range []struct {
+		driver string
+		log    string
+		want   []LineError
+	}{
+		{
+			"NVIDIA",
+			"0(4) : error C1008: undefined variable \"foo\"\n",
+			[]LineError{{File: "shader.frag", Line: 4, Message: `error C1008: undefined variable "foo"`}},
+		},
+		{
+			"NVIDIA with a warning",
+			"0(3) : warning C7022: unrecognized profile specifier \"main\"\n0(4) : error C1008: undefined variable \"foo\"\n",
+			[]LineError{
+				{File: "shader.frag", Line: 3, Message: `warning C7022: unrecognized profile specifier "main"`},
+				{File: "shader.frag", Line: 4, Message: `error C1008: undefined variable "foo"`},
+			},
+		},
+		{
+			"Mesa",
+			"0:4(2): error: `foo' undeclared\n0:4(2): error: value of type float cannot be assigned to variable of type error\n",
+			[]LineError{
+				{File: "shader.frag", Line: 4, Message: "error: `foo' undeclared"},
+				{File: "shader.frag", Line: 4, Message: "error: value of type float cannot be assigned to variable of type error"},
+			},
+		},
+		{
+			"AMD",
+			"Fragment shader failed to compile with the following errors:\nERROR: 0:4: error(#143) Undeclared identifier: foo\n" +
+				"ERROR: error(#273) 1 compilation errors.  No code generated\n",
+			[]LineError{{File: "shader.frag", Line: 4, Message: "error: error(#143) Undeclared identifier: foo"}},
+		},
+		{
+			"Intel",
+			"ERROR: 0:4: 'foo' : undeclared identifier\nERROR: 1 compilation errors.  No code generated.\n",
+			[]LineError{{File: "shader.frag", Line: 4, Message: "error: 'foo' : undeclared identifier"}},
+		},
+		{
+			"a source string which doesn't exist",
+			"3(4) : error C1008: undefined variable \"foo\"\n",
+			nil,
+		},
+	} {
+		e := newCompileError(sources, c.log)
+		for i := range e.Lines {
+			e.Lines[i].Context = nil
+		}
+		if !reflect.DeepEqual(e.Lines, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.driver, e.Lines, c.want)
+		}
+		if e.Log != c.log {
+			t.Errorf("%s: got log %q, want it as it was written", c.driver, e.Log)
+		}
+	}
+}
+
+func TestCompileErrorContext(t *testing.T) {
+	sources := []source{{"shader.frag", "#version 450\r\n\r\nvoid main() {\r\n\tfoo = 1.0;\r\n}"}}
+
+	for _, c := range []struct {
+		line int
+		want []string
+	}{
+		{4, []string{"     2 | ", "     3 | void main() {", ">    4 | \tfoo = 1.0;", "     5 | }"}},
+		{1, []string{">    1 | #version 450", "     2 | ", "     3 | void main() {"}},
+	} {
+		if got := context(strings.Split(sources[0].text, "\n"), c.line); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("around line %d got %q, want %q", c.line, got, c.want)
+		}
+	}
+}
+
+func TestCompileErrorMapsIncludedLinesToTheirFile(t *testing.T) {
+	inDir(t, map[string]string{"lights.glsl": "struct PointLight {\n\tvec3 position;\n\tfloat radius\n};"})
+
+	p, err := preprocess(Fragment("shader.frag", "#version 450\n#include \"lights.glsl\"\n\nvoid main() {\n\tfoo = 1.0;\n}"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// These are the lines Mesa reports for the preprocessed source, numbered by the #line directives rather than by
+	// where they are in p.text.
+	log := "1:4(1): error: syntax error, unexpected '}', expecting ';'\n0:5(2): error: `foo' undeclared\n"
+
+	e := newCompileError(p.sources, log)
+	want := []LineError{
+		{
+			File:    "lights.glsl",
+			Line:    4,
+			Message: "error: syntax error, unexpected '}', expecting ';'",
+			Context: []string{"     2 | \tvec3 position;", "     3 | \tfloat radius", ">    4 | };"},
+		},
+		{
+			File:    "shader.frag",
+			Line:    5,
+			Message: "error: `foo' undeclared",
+			Context: []string{"     3 | ", "     4 | void main() {", ">    5 | \tfoo = 1.0;", "     6 | }"},
+		},
+	}
+	if !reflect.DeepEqual(e.Lines, want) {
+		t.Errorf("got %+v, want %+v", e.Lines, want)
+	}
+	wantError := "failed to compile shader.frag:\nlights.glsl:4: error: syntax error, unexpected '}', expecting ';'"
+	if got := e.Error(); !strings.HasPrefix(got, wantError) {
+		t.Errorf("got error %q, want one starting %q", got, wantError)
+	}
+}
@@ -0,0 +1,143 @@
+package shader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// defines are injected into every stage, in the order they were first defined.
+	defines []define
+
+	includeLine = regexp.MustCompile(`^\s*#\s*include\b`)
+	includeFile = regexp.MustCompile(`^\s*#\s*include\s+"([^"]+)"\s*$`)
+	versionLine = regexp.MustCompile(`^\s*#\s*version\b`)
+)
+
+type define struct {
+	name, value string
+}
+
+// Define sets a macro which is defined in every stage, straight after its #version, so that values which the Go side
+// depends on, such as buffer sizes, are only written down in one place. A program only picks up a changed value when it
+// is next built or reloaded.
+func Define(name string, value interface{}) {
+	v := fmt.Sprint(value)
+	for i, d := range defines {
+		if d.name == name {
+			defines[i].value = v
+			return
+		}
+	}
+	defines = append(defines, define{name, v})
+}
+
+// source is one of the files which make up a stage's source, after preprocessing.
+type source struct {
+	name, text string
+}
+
+// preprocessed is a stage's source after preprocessing, along with every file which went into it. sources[i] is
+// source string number i in #line directives and compile logs, with the stage itself being number 0.
+type preprocessed struct {
+	text     string
+	sources  []source
+	includes []watchedFile
+
+	features []string
+	// expanding is the chain of files being expanded, each included by the one before it.
+	expanding []string
+}
+
+// preprocess expands every #include in stage, and injects the defines, followed by each of features defined as 1, after
+// its #version. An include names a file relative to the working directory, like the files passed to Load, and is only
+// included once however many times it is named, but a file which includes itself, directly or through others, is an
+// error. #line directives are written around everything which is added, so that compile errors refer to the right line
+// of the right file. The files which were included are returned even if preprocessing fails, so that they can be
+// watched.
+func preprocess(stage Stage, features []string) (*preprocessed, error) {
+	p := &preprocessed{features: features}
+	var b strings.Builder
+	err := p.expand(&b, stage.Name, strings.TrimSuffix(stage.Source, "\x00"), true)
+	p.text = b.String()
+	return p, err
+}
+
+// expand writes text, which came from the file called name, to b with its includes expanded.
+func (p *preprocessed) expand(b *strings.Builder, name, text string, root bool) error {
+	number := len(p.sources)
+	p.sources = append(p.sources, source{name, text})
+	p.expanding = append(p.expanding, name)
+	defer func() { p.expanding = p.expanding[:len(p.expanding)-1] }()
+	if !root {
+		fmt.Fprintf(b, "#line 1 %d\n", number)
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		switch {
+		case root && versionLine.MatchString(line):
+			b.WriteString(line + "\n")
+			for _, d := range defines {
+				fmt.Fprintf(b, "#define %s %s\n", d.name, d.value)
+			}
+			for _, f := range p.features {
+				fmt.Fprintf(b, "#define %s 1\n", f)
+			}
+			fmt.Fprintf(b, "#line %d %d\n", i+2, number)
+		case includeLine.MatchString(line):
+			m := includeFile.FindStringSubmatch(line)
+			if m == nil {
+				return fmt.Errorf("failed to preprocess %v:%d: an #include must name a file in quotes", name, i+1)
+			}
+			for j, e := range p.expanding {
+				if e == m[1] {
+					cycle := strings.Join(p.expanding[j:], " -> ") + " -> " + m[1]
+					return fmt.Errorf("failed to include %q in %v:%d: the includes form a cycle, %v", m[1], name, i+1, cycle)
+				}
+			}
+			if p.included(m[1]) {
+				b.WriteString("\n")
+				continue
+			}
+			included, modified, err := readFile(m[1])
+			if err != nil {
+				return fmt.Errorf("failed to include %q in %v:%d: %v", m[1], name, i+1, err)
+			}
+			p.includes = append(p.includes, watchedFile{m[1], modified})
+			if err := p.expand(b, m[1], included, false); err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "#line %d %d\n", i+2, number)
+		default:
+			b.WriteString(line + "\n")
+		}
+	}
+	return nil
+}
+
+// included returns whether the file called name is already part of the source.
+func (p *preprocessed) included(name string) bool {
+	for _, s := range p.sources {
+		if s.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readFile returns the contents of file, and when it was last modified.
+func readFile(file string) (string, time.Time, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	text, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return string(text), info.ModTime(), nil
+}
@@ -0,0 +1,169 @@
+package shader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// inDir changes to a new directory holding files, by name, for the rest of the test, so that they can be included.
+func inDir(t *testing.T, files map[string]string) {
+	dir := t.TempDir()
+	for name, text := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// lineDirective matches a #line directive as preprocess writes them.
+var lineDirective = regexp.MustCompile(`^#line (\d+) (\d+)$`)
+
+// checkLines checks that every line of p's text, other than those preprocess adds, is the line of the source which the
+// #line directives before it say it is, as a compiler would number it.
+func checkLines(t *testing.T, name string, p *preprocessed) {
+	number, line := 0, 1
+	for _, l := range strings.Split(strings.TrimSuffix(p.text, "\n"), "\n") {
+		if m := lineDirective.FindStringSubmatch(l); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			number, _ = strconv.Atoi(m[2])
+			continue
+		}
+		if strings.HasPrefix(l, "#define ") {
+			line++
+			continue
+		}
+		source := strings.Split(p.sources[number].text, "\n")
+		if line > len(source) {
+			t.Errorf("%s: %q is numbered as line %d of %v, which only has %d lines", name, l, line, p.sources[number].name, len(source))
+		} else if want := source[line-1]; l != want && !(l == "" && includeLine.MatchString(want)) {
+			t.Errorf("%s: %q is numbered as line %d of %v, which is %q", name, l, line, p.sources[number].name, want)
+		}
+		line++
+	}
+}
+
+func TestPreprocess(t *testing.T) {
+	saved := defines
+	t.Cleanup(func() { defines = saved })
+	defines = nil
+	Define("TILE_SIZE", 16)
+	Define("MAXIMUM_POINT_LIGHTS", 1024)
+
+	for _, c := range []struct {
+		name     string
+		files    map[string]string
+		stage    string
+		features []string
+		want     string
+		// err is part of the error preprocess should return, or empty if it should succeed.
+		err string
+	}{
+		{
+			name:  "defines after the version",
+			stage: "// A comment.\n#version 450\nvoid main() {}",
+			want:  "// A comment.\n#version 450\n#define TILE_SIZE 16\n#define MAXIMUM_POINT_LIGHTS 1024\n#line 3 0\nvoid main() {}\n",
+		},
+		{
+			name:     "features after the defines",
+			stage:    "#version 450\nvoid main() {}",
+			features: []string{"ALPHA_TEST", "NORMAL_MAP"},
+			want: "#version 450\n#define TILE_SIZE 16\n#define MAXIMUM_POINT_LIGHTS 1024\n#define ALPHA_TEST 1\n#define NORMAL_MAP 1\n" +
+				"#line 2 0\nvoid main() {}\n",
+		},
+		{
+			name:  "nested includes",
+			files: map[string]string{"a.glsl": "#include \"b.glsl\"\nfloat a;", "b.glsl": "float b;"},
+			stage: "#version 450\n#include \"a.glsl\"\nvoid main() {}",
+			want: "#version 450\n#define TILE_SIZE 16\n#define MAXIMUM_POINT_LIGHTS 1024\n#line 2 0\n" +
+				"#line 1 1\n#line 1 2\nfloat b;\n#line 2 1\nfloat a;\n#line 3 0\nvoid main() {}\n",
+		},
+		{
+			name:  "include once",
+			files: map[string]string{"a.glsl": "float a;", "b.glsl": "#include \"a.glsl\"\nfloat b;"},
+			stage: "#version 450\n#include \"a.glsl\"\n#include \"b.glsl\"\n#include \"a.glsl\"\nvoid main() {}",
+			want: "#version 450\n#define TILE_SIZE 16\n#define MAXIMUM_POINT_LIGHTS 1024\n#line 2 0\n" +
+				"#line 1 1\nfloat a;\n#line 3 0\n#line 1 2\n\nfloat b;\n#line 4 0\n\nvoid main() {}\n",
+		},
+		{
+			name:  "line numbers after an include",
+			files: map[string]string{"a.glsl": "float a;\nfloat b;\nfloat c;"},
+			stage: "#version 450\n\n#include \"a.glsl\"\n\nfloat d;\nvoid main() {}",
+			want: "#version 450\n#define TILE_SIZE 16\n#define MAXIMUM_POINT_LIGHTS 1024\n#line 2 0\n\n" +
+				"#line 1 1\nfloat a;\nfloat b;\nfloat c;\n#line 4 0\n\nfloat d;\nvoid main() {}\n",
+		},
+		{
+			name:  "missing include",
+			stage: "#version 450\n#include \"missing.glsl\"",
+			err:   `failed to include "missing.glsl" in shader.frag:2`,
+		},
+		{
+			name:  "unquoted include",
+			stage: "#version 450\n#include <a.glsl>",
+			err:   "shader.frag:2: an #include must name a file in quotes",
+		},
+		{
+			name:  "including itself",
+			files: map[string]string{"a.glsl": "#include \"a.glsl\""},
+			stage: "#version 450\n#include \"a.glsl\"",
+			err:   "the includes form a cycle, a.glsl -> a.glsl",
+		},
+		{
+			name:  "include cycle",
+			files: map[string]string{"a.glsl": "#include \"b.glsl\"", "b.glsl": "#include \"a.glsl\""},
+			stage: "#version 450\n#include \"a.glsl\"",
+			err:   "the includes form a cycle, a.glsl -> b.glsl -> a.glsl",
+		},
+		{
+			name:  "include cycle through the stage",
+			files: map[string]string{"a.glsl": "#include \"shader.frag\""},
+			stage: "#version 450\n#include \"a.glsl\"",
+			err:   "the includes form a cycle, shader.frag -> a.glsl -> shader.frag",
+		},
+	} {
+		inDir(t, c.files)
+		p, err := preprocess(Fragment("shader.frag", c.stage+"\x00"), c.features)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: got error %v, want one containing %q", c.name, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if p.text != c.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", c.name, p.text, c.want)
+		}
+		checkLines(t, c.name, p)
+	}
+}
+
+func TestPreprocessReturnsTheIncludesWhenItFails(t *testing.T) {
+	inDir(t, map[string]string{"a.glsl": "#include \"b.glsl\"", "b.glsl": "#include \"missing.glsl\""})
+
+	p, err := preprocess(Fragment("shader.frag", "#version 450\n#include \"a.glsl\""), nil)
+	if err == nil {
+		t.Fatal("including a missing file succeeded")
+	}
+	var names []string
+	for _, f := range p.includes {
+		names = append(names, f.name)
+	}
+	if got := strings.Join(names, ","); got != "a.glsl,b.glsl" {
+		t.Errorf("got includes %v, want a.glsl,b.glsl so that fixing them reloads the shader", got)
+	}
+}
@@ -0,0 +1,159 @@
+package shader
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/brandonnelson3/GameEngine/buffers"
+	"github.com/brandonnelson3/GameEngine/uniforms"
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+// Uniform is an active uniform in a Program.
+type Uniform struct {
+	Name     string
+	Location int32
+	// Type is the GLSL type, such as gl.FLOAT_MAT4.
+	Type uint32
+	// Size is the number of elements if the uniform is an array, and 1 otherwise.
+	Size int32
+}
+
+// Block is an active shader storage block in a Program.
+type Block struct {
+	Name    string
+	Binding uint32
+}
+
+// uniformTypes is the GLSL type each of the uniforms wrappers is for, and how to make one.
+var uniformTypes = map[reflect.Type]struct {
+	glType uint32
+	make   func(program uint32, location int32) interface{}
+}{
+	reflect.TypeOf(&uniforms.Matrix4{}):   {gl.FLOAT_MAT4, func(p uint32, l int32) interface{} { return uniforms.NewMatrix4(p, l) }},
+	reflect.TypeOf(&uniforms.Int{}):       {gl.INT, func(p uint32, l int32) interface{} { return uniforms.NewInt(p, l) }},
+	reflect.TypeOf(&uniforms.UInt{}):      {gl.UNSIGNED_INT, func(p uint32, l int32) interface{} { return uniforms.NewUInt(p, l) }},
+	reflect.TypeOf(&uniforms.Sampler2D{}): {gl.SAMPLER_2D, func(p uint32, l int32) interface{} { return uniforms.NewSampler2D(p, l) }},
+	reflect.TypeOf(&uniforms.UIVector2{}): {gl.UNSIGNED_INT_VEC2, func(p uint32, l int32) interface{} { return uniforms.NewUIVector2(p, l) }},
+	reflect.TypeOf(&uniforms.IVector2{}):  {gl.INT_VEC2, func(p uint32, l int32) interface{} { return uniforms.NewIVector2(p, l) }},
+	reflect.TypeOf(&uniforms.Vector4{}):   {gl.FLOAT_VEC4, func(p uint32, l int32) interface{} { return uniforms.NewVector4(p, l) }},
+}
+
+var bindingType = reflect.TypeOf(&buffers.Binding{})
+
+// readResources reads this Program's active uniforms and shader storage blocks.
+func (p *Program) readResources() {
+	p.Uniforms = make(map[string]Uniform)
+	for i, n := uint32(0), p.resourceCount(gl.UNIFORM); i < n; i++ {
+		props := []uint32{gl.BLOCK_INDEX, gl.LOCATION, gl.TYPE, gl.ARRAY_SIZE}
+		values := make([]int32, len(props))
+		gl.GetProgramResourceiv(p.id, gl.UNIFORM, i, int32(len(props)), &props[0], int32(len(values)), nil, &values[0])
+		// Uniforms inside a block don't have a location of their own.
+		if values[0] != -1 {
+			continue
+		}
+		name := p.resourceName(gl.UNIFORM, i)
+		p.Uniforms[name] = Uniform{Name: name, Location: values[1], Type: uint32(values[2]), Size: values[3]}
+	}
+
+	p.Blocks = make(map[string]Block)
+	for i, n := uint32(0), p.resourceCount(gl.SHADER_STORAGE_BLOCK); i < n; i++ {
+		prop := uint32(gl.BUFFER_BINDING)
+		var binding int32
+		gl.GetProgramResourceiv(p.id, gl.SHADER_STORAGE_BLOCK, i, 1, &prop, 1, nil, &binding)
+		name := p.resourceName(gl.SHADER_STORAGE_BLOCK, i)
+		p.Blocks[name] = Block{Name: name, Binding: uint32(binding)}
+	}
+}
+
+func (p *Program) resourceCount(programInterface uint32) uint32 {
+	var n int32
+	gl.GetProgramInterfaceiv(p.id, programInterface, gl.ACTIVE_RESOURCES, &n)
+	return uint32(n)
+}
+
+func (p *Program) resourceName(programInterface, index uint32) string {
+	prop := uint32(gl.NAME_LENGTH)
+	var length int32
+	gl.GetProgramResourceiv(p.id, programInterface, index, 1, &prop, 1, nil, &length)
+	if length <= 0 {
+		return ""
+	}
+	name := make([]uint8, length)
+	gl.GetProgramResourceName(p.id, programInterface, index, length, nil, &name[0])
+	// Array uniforms are named like lights[0], but are set through the location of their first element.
+	return strings.TrimSuffix(strings.TrimRight(string(name), "\x00"), "[0]")
+}
+
+// Populate fills in every exported field of the struct target points to which is a uniforms wrapper, such as
+// *uniforms.Matrix4, or a *buffers.Binding. A uniform field is bound to the uniform named by its `uniform` tag, or to
+// its own name with the first letter lowered, and a binding field to the shader storage block named by its `buffer` tag
+// or its own name. An error is returned if any of them isn't active in the program, or has a different type, unless its
+// tag is marked optional like `uniform:",optional"`, for a struct which is populated from several Variants which don't
+// all use it. A missing optional uniform is set to a wrapper which does nothing, and a missing optional binding to nil.
+func (p *Program) Populate(target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("can't populate %T, which isn't a pointer to a struct", target)
+	}
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if f.Type == bindingType {
+			name, optional := parseTag(f.Tag.Get("buffer"))
+			if name == "" {
+				name = f.Name
+			}
+			b, ok := p.Blocks[name]
+			if !ok && optional {
+				v.Field(i).Set(reflect.Zero(f.Type))
+				continue
+			}
+			if !ok {
+				return fmt.Errorf("%v has no active buffer %q for %s", p.name, name, f.Name)
+			}
+			v.Field(i).Set(reflect.ValueOf(buffers.NewBinding(b.Binding)))
+			continue
+		}
+		ut, ok := uniformTypes[f.Type]
+		if !ok {
+			continue
+		}
+		name, optional := parseTag(f.Tag.Get("uniform"))
+		if name == "" {
+			name = lowerFirst(f.Name)
+		}
+		u, ok := p.Uniforms[name]
+		if !ok && optional {
+			// OpenGL ignores any uniform set at location -1.
+			v.Field(i).Set(reflect.ValueOf(ut.make(p.id, -1)))
+			continue
+		}
+		if !ok {
+			return fmt.Errorf("%v has no active uniform %q for %s", p.name, name, f.Name)
+		}
+		if u.Type != ut.glType {
+			return fmt.Errorf("%v uniform %q is type 0x%x, but %s needs type 0x%x", p.name, name, u.Type, f.Name, ut.glType)
+		}
+		v.Field(i).Set(reflect.ValueOf(ut.make(p.id, u.Location)))
+	}
+	return nil
+}
+
+// parseTag splits a `uniform` or `buffer` tag into its name and whether it is optional.
+func parseTag(tag string) (string, bool) {
+	name, options, _ := strings.Cut(tag, ",")
+	return name, options == "optional"
+}
+
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
@@ -0,0 +1,181 @@
+package shader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+// pollInterval is how often the files of loaded programs are checked for changes.
+const pollInterval = 500 * time.Millisecond
+
+var (
+	// ReloadTopic receives a Reload whenever a loaded program's files change and it is rebuilt.
+	ReloadTopic = messagebus.NewTopic[Reload]("shaderreload")
+
+	// watched are the programs which were loaded from files, and are rebuilt when they change.
+	watched  []*Program
+	lastPoll time.Time
+)
+
+// Reload is message data which describes a program being rebuilt after its files changed.
+type Reload struct {
+	// Program is the names of the program's stages.
+	Program string
+	// Err is why the program couldn't be rebuilt, in which case it is left as it was, or why it no longer matches what
+	// uses it, or nil.
+	Err error
+}
+
+type watchedFile struct {
+	name     string
+	modified time.Time
+}
+
+// stageTypes is the kind of shader each file extension is for.
+var stageTypes = map[string]uint32{
+	".vert": gl.VERTEX_SHADER,
+	".frag": gl.FRAGMENT_SHADER,
+	".comp": gl.COMPUTE_SHADER,
+}
+
+// Load reads each of the provided files as a stage, depending on whether its extension is .vert, .frag or .comp, and
+// builds them into a Program as Build does. The files, and every file they include, are watched by Update, which
+// rebuilds the program whenever one of them changes.
+func Load(files ...string) (*Program, error) {
+	return load(files, nil)
+}
+
+// load loads files into a Program as Load does, with each of features defined in them.
+func load(files []string, features []string) (*Program, error) {
+	stages, watching, err := readStages(files)
+	if err != nil {
+		return nil, err
+	}
+	p, err := build(stages, features)
+	if err != nil {
+		return nil, err
+	}
+	p.files = files
+	p.watching = append(watching, p.watching...)
+	watched = append(watched, p)
+	return p, nil
+}
+
+// readStages reads each of files as a stage, returning them along with when each file was last modified.
+func readStages(files []string) ([]Stage, []watchedFile, error) {
+	var stages []Stage
+	var watching []watchedFile
+	for _, file := range files {
+		t, ok := stageTypes[filepath.Ext(file)]
+		if !ok {
+			return nil, nil, fmt.Errorf("failed to load %q: the extension isn't one of .vert, .frag or .comp", file)
+		}
+		source, modified, err := readFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load %q: %v", file, err)
+		}
+		stages = append(stages, Stage{Type: t, Name: file, Source: source})
+		watching = append(watching, watchedFile{file, modified})
+	}
+	return stages, watching, nil
+}
+
+// OnReload adds a function to be called whenever this Program is rebuilt, which needs to find everything in it again
+// such as by calling Populate. Uniforms lose their values when the program is rebuilt, so anything which isn't set every
+// frame needs to be set again too.
+func (p *Program) OnReload(f func() error) {
+	p.onReload = append(p.onReload, f)
+}
+
+// Update is intended to be called once per frame, on the thread which owns the OpenGL context. Every so often it checks
+// whether the files of any loaded Program have changed, and rebuilds those which have.
+func Update() {
+	if time.Since(lastPoll) < pollInterval {
+		return
+	}
+	lastPoll = time.Now()
+	for _, p := range watched {
+		if p.changed() {
+			p.reload()
+		}
+	}
+}
+
+// changed returns whether any of this Program's files have been modified since they were last read.
+func (p *Program) changed() bool {
+	changed := false
+	for i, f := range p.watching {
+		info, err := os.Stat(f.name)
+		if err != nil {
+			// The file may be part way through being saved, so it is checked again next time.
+			continue
+		}
+		if !info.ModTime().Equal(f.modified) {
+			p.watching[i].modified = info.ModTime()
+			changed = true
+		}
+	}
+	return changed
+}
+
+// reload rebuilds this Program from its files. The new stages are linked into a scratch program first, so that the
+// program is left as it was if they don't work. Otherwise they are linked into the program itself, rather than a new one,
+// so that every pipeline which uses it picks up the change.
+func (p *Program) reload() {
+	stages, watching, err := readStages(p.files)
+	if err != nil {
+		p.reloaded(err)
+		return
+	}
+
+	scratch := gl.CreateProgram()
+	includes, err := link(scratch, stages, p.features)
+	gl.DeleteProgram(scratch)
+	// The includes may have changed too, and need watching even if they're broken so that fixing them is picked up.
+	p.watching = append(watching, includes...)
+	if err == nil {
+		_, err = link(p.id, stages, p.features)
+	}
+	if err != nil {
+		p.reloaded(err)
+		return
+	}
+	p.readResources()
+	for _, f := range p.onReload {
+		if err := f(); err != nil {
+			logf("Reloaded %v, but it no longer matches its Go side: %v", p.name, err)
+			ReloadTopic.Publish(Reload{Program: p.name, Err: err})
+			return
+		}
+	}
+	p.reloaded(nil)
+}
+
+// reloaded reports the outcome of rebuilding this Program, where err is why it was left as it was.
+func (p *Program) reloaded(err error) {
+	if err != nil {
+		logf("Failed to reload %v, keeping the previous program: %v", p.name, err)
+	} else {
+		logf("Reloaded %v", p.name)
+	}
+	ReloadTopic.Publish(Reload{Program: p.name, Err: err})
+}
+
+// unwatch stops checking the files of p for changes.
+func unwatch(p *Program) {
+	for i, w := range watched {
+		if w == p {
+			watched = append(watched[:i], watched[i+1:]...)
+			return
+		}
+	}
+}
+
+func logf(format string, args ...interface{}) {
+	messagebus.SendAsync(&messagebus.Message{System: "Shader", Type: "log", Data1: fmt.Sprintf(format, args...)})
+}
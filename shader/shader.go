@@ -0,0 +1,174 @@
+package shader
+
+import (
+	"strings"
+
+	"github.com/go-gl/gl/v4.5-core/gl"
+)
+
+// Stage is the source of a single shader stage, such as a vertex or fragment shader.
+type Stage struct {
+	// Type is the kind of shader, such as gl.VERTEX_SHADER.
+	Type uint32
+	// Name is the file the source came from, which errors refer to it by.
+	Name   string
+	Source string
+}
+
+// Vertex returns a vertex shader Stage.
+func Vertex(name, source string) Stage {
+	return Stage{gl.VERTEX_SHADER, name, source}
+}
+
+// Fragment returns a fragment shader Stage.
+func Fragment(name, source string) Stage {
+	return Stage{gl.FRAGMENT_SHADER, name, source}
+}
+
+// Compute returns a compute shader Stage.
+func Compute(name, source string) Stage {
+	return Stage{gl.COMPUTE_SHADER, name, source}
+}
+
+// Program is a linked shader program, along with everything it exposes to be set from Go.
+type Program struct {
+	id   uint32
+	name string
+	// features are defined in every stage, on top of the defines, for a program which is one of a shader's Variants.
+	features []string
+
+	// Uniforms are the program's active uniforms outside of any block, by name.
+	Uniforms map[string]Uniform
+	// Blocks are the program's active shader storage blocks, by name.
+	Blocks map[string]Block
+
+	// files are the files the stages were loaded from, if any.
+	files []string
+	// watching is every file which went into the stages, including those they include, along with when each was last
+	// modified.
+	watching []watchedFile
+	onReload []func() error
+}
+
+// Build compiles the provided stages, after expanding their #includes and injecting the defines, and links them into a
+// Program. The program is separable, so that it can be used
+// in a pipeline, unless it is a compute shader. A compile failure is returned as a *CompileError, and a link failure as a
+// *LinkError.
+func Build(stages ...Stage) (*Program, error) {
+	return build(stages, nil)
+}
+
+// build builds stages into a Program as Build does, with each of features defined in them.
+func build(stages []Stage, features []string) (*Program, error) {
+	p := &Program{id: gl.CreateProgram(), name: stageNames(stages), features: features}
+	includes, err := link(p.id, stages, features)
+	if err != nil {
+		gl.DeleteProgram(p.id)
+		return nil, err
+	}
+	p.watching = includes
+	p.readResources()
+	return p, nil
+}
+
+// link compiles stages, with each of features defined in them, and links them into program, which is left unchanged if
+// they fail to compile. It returns every file the stages included, even if they fail.
+func link(program uint32, stages []Stage, features []string) ([]watchedFile, error) {
+	var includes []watchedFile
+	var shaders []uint32
+	attached := false
+	defer func() {
+		for _, s := range shaders {
+			if attached {
+				gl.DetachShader(program, s)
+			}
+			gl.DeleteShader(s)
+		}
+	}()
+
+	separable := true
+	for _, stage := range stages {
+		if stage.Type == gl.COMPUTE_SHADER {
+			separable = false
+		}
+		s, included, err := compile(stage, features)
+		includes = append(includes, included...)
+		if err != nil {
+			return includes, err
+		}
+		shaders = append(shaders, s)
+	}
+	for _, s := range shaders {
+		gl.AttachShader(program, s)
+	}
+	attached = true
+	if separable {
+		gl.ProgramParameteri(program, gl.PROGRAM_SEPARABLE, gl.TRUE)
+	}
+	gl.LinkProgram(program)
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+
+		return includes, &LinkError{Name: stageNames(stages), Log: strings.TrimRight(log, "\x00")}
+	}
+	return includes, nil
+}
+
+func stageNames(stages []Stage) string {
+	var names []string
+	for _, stage := range stages {
+		names = append(names, stage.Name)
+	}
+	return strings.Join(names, "+")
+}
+
+// compile preprocesses and compiles a single stage, returning the shader and the files it included.
+func compile(stage Stage, features []string) (uint32, []watchedFile, error) {
+	source, err := preprocess(stage, features)
+	if err != nil {
+		return 0, source.includes, err
+	}
+
+	shader := gl.CreateShader(stage.Type)
+	csources, free := gl.Strs(source.text + "\x00")
+	gl.ShaderSource(shader, 1, csources, nil)
+	free()
+	gl.CompileShader(shader)
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+
+		gl.DeleteShader(shader)
+		return 0, source.includes, newCompileError(source.sources, strings.TrimRight(log, "\x00"))
+	}
+	return shader, source.includes, nil
+}
+
+// ID returns the OpenGL name of this Program.
+func (p *Program) ID() uint32 {
+	return p.id
+}
+
+// Name returns the names of the stages this Program was built from.
+func (p *Program) Name() string {
+	return p.name
+}
+
+// Delete frees this Program, after which it can't be used.
+func (p *Program) Delete() {
+	unwatch(p)
+	gl.DeleteProgram(p.id)
+}
@@ -0,0 +1,60 @@
+package shader
+
+import (
+	"sort"
+	"strings"
+)
+
+// Variants is a shader loaded from files, which is built into a separate Program for each set of features it is asked
+// for. Each feature is defined as 1 in the stages, so that everything a program doesn't need can be left out of it with
+// #ifdef rather than branched around at runtime.
+type Variants struct {
+	files    []string
+	programs map[string]*Program
+}
+
+// NewVariants instantiates Variants of the shader made up of the provided files, as they would be passed to Load. No
+// program is built until it is asked for.
+func NewVariants(files ...string) *Variants {
+	return &Variants{files: files, programs: make(map[string]*Program)}
+}
+
+// Get returns the Program with the provided features defined, in any order, building and loading it the first time
+// they are asked for. Every program which is built is watched and reloaded as Load's are.
+func (v *Variants) Get(features ...string) (*Program, error) {
+	features = normalize(features)
+	k := strings.Join(features, ",")
+	if p, ok := v.programs[k]; ok {
+		return p, nil
+	}
+	p, err := load(v.files, features)
+	if err != nil {
+		return nil, err
+	}
+	if k != "" {
+		p.name += "[" + k + "]"
+	}
+	v.programs[k] = p
+	return p, nil
+}
+
+// Delete frees every Program which has been built.
+func (v *Variants) Delete() {
+	for k, p := range v.programs {
+		p.Delete()
+		delete(v.programs, k)
+	}
+}
+
+// normalize returns features sorted and without duplicates, so that every order of the same features is the same set.
+func normalize(features []string) []string {
+	sorted := append([]string(nil), features...)
+	sort.Strings(sorted)
+	var unique []string
+	for i, f := range sorted {
+		if i == 0 || f != sorted[i-1] {
+			unique = append(unique, f)
+		}
+	}
+	return unique
+}
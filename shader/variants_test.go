@@ -0,0 +1,34 @@
+package shader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeGivesEachSetOfFeaturesOneKey(t *testing.T) {
+	for _, c := range []struct {
+		features []string
+		want     string
+	}{
+		{nil, ""},
+		{[]string{}, ""},
+		{[]string{"SHADOWS"}, "SHADOWS"},
+		{[]string{"SHADOWS", "SHADOWS"}, "SHADOWS"},
+		{[]string{"SHADOWS", "NORMAL_MAP"}, "NORMAL_MAP,SHADOWS"},
+		{[]string{"NORMAL_MAP", "SHADOWS"}, "NORMAL_MAP,SHADOWS"},
+		{[]string{"SHADOWS", "NORMAL_MAP", "SHADOWS", "ANTI_TILING", "NORMAL_MAP"}, "ANTI_TILING,NORMAL_MAP,SHADOWS"},
+	} {
+		if got := strings.Join(normalize(c.features), ","); got != c.want {
+			t.Errorf("%v has the key %q, want %q", c.features, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeLeavesItsArgumentAlone(t *testing.T) {
+	features := []string{"SHADOWS", "NORMAL_MAP", "SHADOWS"}
+	normalize(features)
+	if want := []string{"SHADOWS", "NORMAL_MAP", "SHADOWS"}; !reflect.DeepEqual(features, want) {
+		t.Errorf("normalize changed its argument to %v, want it left as %v", features, want)
+	}
+}
@@ -20,7 +20,11 @@ func NewFromPng(file string) (uint32, error) {
 	if err != nil {
 		return 0, err
 	}
+	return New(img)
+}
 
+// New builds a texture from the provided image.
+func New(img image.Image) (uint32, error) {
 	rgba := image.NewRGBA(img.Bounds())
 	if rgba.Stride != rgba.Rect.Size().X*4 {
 		return 0, fmt.Errorf("unsupported stride")
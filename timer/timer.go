@@ -1,12 +1,29 @@
 package timer
 
-import "github.com/go-gl/glfw/v3.1/glfw"
+import (
+	"sync/atomic"
+
+	"github.com/brandonnelson3/GameEngine/messagebus"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
 
 var (
 	previousTime    float64
 	previousElapsed float64
+	frameNumber     atomic.Uint64
+
+	// FrameTopic receives a Frame at the beginning of every frame.
+	FrameTopic = messagebus.NewTopic[Frame]("frame")
 )
 
+// Frame is message data which describes the frame which is beginning.
+type Frame struct {
+	// Number counts up from 1 for the first frame.
+	Number uint64
+	// Length is the time in seconds of the previous frame.
+	Length float64
+}
+
 func init() {
 	previousTime = 0
 	previousElapsed = 0
@@ -17,6 +34,7 @@ func BeginningOfFrame() {
 	time := glfw.GetTime()
 	previousElapsed = time - previousTime
 	previousTime = time
+	FrameTopic.Publish(Frame{Number: frameNumber.Add(1), Length: previousElapsed})
 }
 
 // GetPreviousFrameLength returns the time in seconds as a float64 of the previous frame.
@@ -24,6 +42,12 @@ func GetPreviousFrameLength() float64 {
 	return previousElapsed
 }
 
+// GetFrameNumber returns the number of the current frame, starting from 1 for the first frame. It is safe to call from
+// any goroutine.
+func GetFrameNumber() uint64 {
+	return frameNumber.Load()
+}
+
 // GetTime returns the current time.Now().
 func GetTime() float64 {
 	return glfw.GetTime()
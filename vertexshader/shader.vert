@@ -0,0 +1,28 @@
+#version 450
+
+uniform mat4 projection;
+uniform mat4 view;
+uniform mat4 model;
+
+in vec3 vert;
+in vec3 norm;
+in vec2 uv;
+
+out gl_PerVertex
+{
+    vec4 gl_Position;
+};
+
+out VERTEX_OUT
+{
+	vec3 worldPosition;
+	vec3 normal;
+	vec2 uv;
+} vertex_out;
+
+void main() {
+    gl_Position = projection * view * model * vec4(vert, 1);
+	vertex_out.worldPosition = vec3(model * vec4(vert, 1));
+	vertex_out.normal = vec3(vec4(norm, 1));
+	vertex_out.uv = uv;
+}
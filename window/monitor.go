@@ -0,0 +1,109 @@
+package window
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// VideoMode is a resolution and refresh rate a monitor can be switched to.
+type VideoMode struct {
+	Width, Height int
+	RefreshRate   int
+}
+
+// String returns this VideoMode written like 1920x1080@60Hz.
+func (m VideoMode) String() string {
+	return fmt.Sprintf("%dx%d@%dHz", m.Width, m.Height, m.RefreshRate)
+}
+
+// MonitorInfo describes a connected monitor.
+type MonitorInfo struct {
+	Name string
+	// X and Y are the position of the monitor on the virtual desktop, in screen coordinates.
+	X, Y int
+	// PhysicalWidth and PhysicalHeight are the size of the monitor's display area in millimetres, which is 0 if the
+	// monitor doesn't report it.
+	PhysicalWidth, PhysicalHeight int
+	// ContentScale is how much larger than normal things need to be drawn on this monitor to appear the normal size.
+	ContentScale float32
+	// Current is the video mode the monitor is currently in.
+	Current VideoMode
+	// Modes is every video mode the monitor supports, from smallest to largest.
+	Modes []VideoMode
+}
+
+// GetMonitors returns every connected monitor, with the primary monitor first. The index of a monitor in this list is
+// what config.Window.Monitor selects. glfw must be initialized first.
+func GetMonitors() []MonitorInfo {
+	var infos []MonitorInfo
+	for _, m := range glfw.GetMonitors() {
+		info := MonitorInfo{
+			Name:         m.GetName(),
+			ContentScale: contentScale(m),
+			Current:      videoMode(m.GetVideoMode()),
+		}
+		info.X, info.Y = m.GetPos()
+		info.PhysicalWidth, info.PhysicalHeight = m.GetPhysicalSize()
+		for _, vm := range m.GetVideoModes() {
+			info.Modes = append(info.Modes, videoMode(vm))
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// getMonitor returns the monitor at the provided index in GetMonitors.
+func getMonitor(index int) (*glfw.Monitor, error) {
+	monitors := glfw.GetMonitors()
+	if index < 0 || index >= len(monitors) {
+		return nil, fmt.Errorf("monitor %d doesn't exist, as there are %d monitors", index, len(monitors))
+	}
+	return monitors[index], nil
+}
+
+// findVideoMode returns the video mode m supports at the provided resolution and refresh rate, or at the highest refresh
+// rate available at that resolution if refreshRate is 0.
+func findVideoMode(m *glfw.Monitor, width, height, refreshRate int) (VideoMode, error) {
+	var found VideoMode
+	var available []string
+	for _, vm := range m.GetVideoModes() {
+		mode := videoMode(vm)
+		available = append(available, mode.String())
+		if mode.Width != width || mode.Height != height {
+			continue
+		}
+		if mode.RefreshRate == refreshRate || (refreshRate == 0 && mode.RefreshRate > found.RefreshRate) {
+			found = mode
+		}
+	}
+	if found.Width == 0 {
+		want := fmt.Sprintf("%dx%d", width, height)
+		if refreshRate != 0 {
+			want = VideoMode{width, height, refreshRate}.String()
+		}
+		return VideoMode{}, fmt.Errorf("monitor %q doesn't support %s, only %s", m.GetName(), want, strings.Join(available, ", "))
+	}
+	return found, nil
+}
+
+// contentScale returns how much larger than normal things need to be drawn on m to appear the normal size, as set by the
+// user in their platform's display settings.
+func contentScale(m *glfw.Monitor) float32 {
+	if m == nil {
+		return 1
+	}
+	x, _ := m.GetContentScale()
+	if x <= 0 {
+		return 1
+	}
+	return x
+}
+
+func videoMode(vm *glfw.VidMode) VideoMode {
+	if vm == nil {
+		return VideoMode{}
+	}
+	return VideoMode{vm.Width, vm.Height, vm.RefreshRate}
+}
@@ -1,82 +1,325 @@
 package window
 
 import (
+	"fmt"
+	"math"
+
+	"github.com/brandonnelson3/GameEngine/config"
+	"github.com/brandonnelson3/GameEngine/input"
 	"github.com/brandonnelson3/GameEngine/messagebus"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
+// Mode is how the window is shown.
+type Mode int
+
 const (
-	TileSize = 16
+	// Windowed is a normal window with a border, which can be resized.
+	Windowed Mode = iota
+	// Fullscreen takes over the monitor, switching it to the configured fullscreen resolution.
+	Fullscreen
+	// Borderless covers the monitor at its current resolution, so switching to and from it is quick.
+	Borderless
 )
 
 var (
-	// Width is the width of the window.
-	Width = uint32(1920)
-
-	// Height is the height of the window.
-	Height = uint32(1080)
+	// ResizeTopic receives a Resize whenever the size of the window's framebuffer changes, after GetWidth and GetHeight
+	// have been updated. Everything which depends on the size of the window is expected to be rebuilt when it is received.
+	ResizeTopic = messagebus.NewTopic[Resize]("resize")
 
-	// Title is the title of the window.
-	Title = "Game Engine Demo"
+	// size is the size of the window's framebuffer, in pixels.
+	size Resize
+	// fov is the field of view, which starts at the configured field of view and is changed by zooming.
+	fov float32
 
-	// Near is the near plane of this window.
-	Near = float32(0.1)
+	window   *glfw.Window
+	headless bool
+	mode     = Windowed
+	// monitor is the monitor the window is shown on in Fullscreen and Borderless modes, and fullscreenMode is the video
+	// mode it is switched to in Fullscreen mode.
+	monitor        *glfw.Monitor
+	fullscreenMode VideoMode
+	// windowedX, windowedY, windowedWidth and windowedHeight are where the window was before it left Windowed mode, so
+	// that it can be put back.
+	windowedX, windowedY          int
+	windowedWidth, windowedHeight int
+)
 
-	// Far is the far plane of this window.
-	Far = float32(1000.0)
+// Resize is message data which is sent whenever the size of the window's framebuffer changes.
+type Resize struct {
+	Width, Height uint32
+}
 
-	// Fov is the field of view.
-	Fov = float32(45.0)
+// Create creates a new window, as set up by the current config, on the configured monitor.
+func Create() (*glfw.Window, error) {
+	c := config.Get()
+	fov = c.Projection.Fov
 
-	window *glfw.Window
-)
+	headless = c.Window.Headless
+	size = Resize{c.Window.Width, c.Window.Height}
+	if headless {
+		// Everything is rendered offscreen at exactly the configured size, so the window only needs to hold the context.
+		glfw.WindowHint(glfw.Visible, glfw.False)
+	} else {
+		m, err := getMonitor(c.Window.Monitor)
+		if err != nil {
+			return nil, err
+		}
+		monitor = m
+		fullscreenWidth, fullscreenHeight := c.Window.FullscreenSize()
+		fullscreenMode, err = findVideoMode(m, int(fullscreenWidth), int(fullscreenHeight), c.Window.RefreshRate)
+		if err != nil {
+			if c.Window.Mode == config.Fullscreen {
+				return nil, err
+			}
+			// Nothing needs the video mode until Fullscreen mode is used, which then keeps the monitor as it is.
+			fullscreenMode = videoMode(m.GetVideoMode())
+		}
+	}
 
-// Create creates a new window.
-func Create() *glfw.Window {
-	glfw.WindowHint(glfw.Resizable, glfw.False)
+	glfw.WindowHint(glfw.Resizable, glfw.True)
+	glfw.WindowHint(glfw.Samples, c.Window.Samples)
 	glfw.WindowHint(glfw.ContextVersionMajor, 4)
 	glfw.WindowHint(glfw.ContextVersionMinor, 5)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-	w, err := glfw.CreateWindow(int(Width), int(Height), Title, nil, nil)
+	w, err := glfw.CreateWindow(int(size.Width), int(size.Height), c.Window.Title, nil, nil)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to create window: %v", err)
 	}
-	w.SetInputMode(glfw.CursorMode, glfw.CursorHidden)
 	window = w
-	messagebus.RegisterType("key", handleEscape)
-	return w
+	input.SubscribeActions(messagebus.PriorityUI, handleQuit)
+	if headless {
+		return w, nil
+	}
+
+	if c.Window.ScaleToContent {
+		scaleToContent(w, monitor)
+	}
+	// Windows open on the primary monitor, so it is moved to the middle of the configured one.
+	if vm := monitor.GetVideoMode(); vm != nil {
+		x, y := monitor.GetPos()
+		width, height := w.GetSize()
+		w.SetPos(x+(vm.Width-width)/2, y+(vm.Height-height)/2)
+	}
+	// The framebuffer is larger than the window on high DPI displays, and everything is rendered at its size.
+	if width, height := w.GetFramebufferSize(); width > 0 && height > 0 {
+		size = Resize{uint32(width), uint32(height)}
+	}
+	w.SetFramebufferSizeCallback(framebufferSizeCallback)
+	SetCursorCaptured(true)
+	input.SubscribeActions(messagebus.PriorityUI, handleToggleCursorCapture)
+	input.SubscribeActions(messagebus.PriorityUI, handleToggleFullscreen)
+
+	switch c.Window.Mode {
+	case config.Fullscreen:
+		SetMode(Fullscreen)
+	case config.Borderless:
+		SetMode(Borderless)
+	}
+	return w, nil
+}
+
+// scaleToContent scales w up by the content scale of m, so that it is the same size on a high DPI monitor as on any
+// other, without letting it grow past the monitor's video mode. Platforms which make the framebuffer larger than the
+// window instead, such as macOS, already show it at the same size, so it is left as it is there.
+func scaleToContent(w *glfw.Window, m *glfw.Monitor) {
+	width, height := w.GetSize()
+	framebufferWidth, _ := w.GetFramebufferSize()
+	vm := m.GetVideoMode()
+	if width <= 0 || height <= 0 || framebufferWidth <= 0 || vm == nil {
+		return
+	}
+	scale := float64(contentScale(m)) * float64(width) / float64(framebufferWidth)
+	scale = math.Min(scale, math.Min(float64(vm.Width)/float64(width), float64(vm.Height)/float64(height)))
+	if scale != 1 {
+		w.SetSize(int(math.Round(float64(width)*scale)), int(math.Round(float64(height)*scale)))
+	}
+}
+
+// IsHeadless returns whether the window is hidden, in which case everything must be rendered offscreen at GetWidth by
+// GetHeight, which never change.
+func IsHeadless() bool {
+	return headless
+}
+
+// GetMode returns how the window is currently shown.
+func GetMode() Mode {
+	return mode
+}
+
+// SetMode switches how the window is shown, on the monitor picked by the config or SetVideoMode. A Resize is sent once
+// the window has changed size.
+func SetMode(m Mode) {
+	if m == mode {
+		return
+	}
+	if mode == Windowed {
+		windowedX, windowedY = window.GetPos()
+		windowedWidth, windowedHeight = window.GetSize()
+	}
+	mode = m
+
+	switch m {
+	case Windowed:
+		window.SetMonitor(nil, windowedX, windowedY, windowedWidth, windowedHeight, 0)
+	case Fullscreen:
+		window.SetMonitor(monitor, 0, 0, fullscreenMode.Width, fullscreenMode.Height, fullscreenMode.RefreshRate)
+	case Borderless:
+		vm := monitor.GetVideoMode()
+		window.SetMonitor(monitor, 0, 0, vm.Width, vm.Height, vm.RefreshRate)
+	}
+}
+
+// SetVideoMode picks the monitor, by its index in GetMonitors, and the video mode which are used in Fullscreen mode, and
+// also in Borderless mode for the monitor. A RefreshRate of 0 picks the highest available. The window switches straight
+// away if it is already in either mode, and the choice is stored in the config so that config.Save remembers it.
+func SetVideoMode(monitorIndex int, vm VideoMode) error {
+	if headless {
+		return fmt.Errorf("a headless window has no video mode")
+	}
+	m, err := getMonitor(monitorIndex)
+	if err != nil {
+		return err
+	}
+	found, err := findVideoMode(m, vm.Width, vm.Height, vm.RefreshRate)
+	if err != nil {
+		return err
+	}
+
+	c := config.Get()
+	c.Window.Monitor = monitorIndex
+	c.Window.FullscreenWidth, c.Window.FullscreenHeight = uint32(found.Width), uint32(found.Height)
+	c.Window.RefreshRate = vm.RefreshRate
+	if err := config.Set(c); err != nil {
+		return err
+	}
+
+	monitor, fullscreenMode = m, found
+	if current := mode; current != Windowed {
+		// Forcing the mode to be applied again moves the window onto the new monitor or video mode.
+		mode = Windowed
+		window.SetMonitor(nil, windowedX, windowedY, windowedWidth, windowedHeight, 0)
+		SetMode(current)
+	}
+	return nil
+}
+
+// GetContentScale returns how many framebuffer pixels make up each pixel of anything drawn at a fixed size, such as the
+// pip, so that it appears the same size on a high DPI monitor as on any other. It is 1 for a headless window, which
+// renders at exactly its configured size wherever it is.
+func GetContentScale() float32 {
+	if headless {
+		return 1
+	}
+	scale, _ := window.GetContentScale()
+	if scale <= 0 {
+		return 1
+	}
+	return scale
+}
+
+// framebufferSizeCallback is the function bound to handle framebuffer resize events from OpenGL.
+func framebufferSizeCallback(w *glfw.Window, width, height int) {
+	// A minimized window has no framebuffer, and there is nothing to rebuild until it is restored.
+	if width <= 0 || height <= 0 {
+		return
+	}
+	if uint32(width) == size.Width && uint32(height) == size.Height {
+		return
+	}
+	size = Resize{uint32(width), uint32(height)}
+	ResizeTopic.Publish(size)
+}
+
+// GetWidth returns the width of the window's framebuffer, in pixels.
+func GetWidth() uint32 {
+	return size.Width
+}
+
+// GetHeight returns the height of the window's framebuffer, in pixels.
+func GetHeight() uint32 {
+	return size.Height
+}
+
+// GetFov returns the field of view in degrees, which starts at the configured field of view and is changed by zooming.
+func GetFov() float32 {
+	return fov
+}
+
+// SetFov sets the field of view, in degrees.
+func SetFov(f float32) {
+	fov = f
 }
 
 // GetProjection returns the projection matrix.
 func GetProjection() mgl32.Mat4 {
-	return mgl32.Perspective(mgl32.DegToRad(Fov), float32(Width)/float32(Height), Near, Far)
+	p := config.Get().Projection
+	return mgl32.Perspective(mgl32.DegToRad(fov), float32(size.Width)/float32(size.Height), p.Near, p.Far)
 }
 
-// RecenterCursor recenters the mouse in this window.
-func RecenterCursor() {
-	window.SetCursorPos(float64(Width)/2, float64(Height)/2)
+// SetCursorCaptured captures or releases the cursor. While captured the cursor is hidden and unbounded, and mouse motion
+// is turned into input.MouseDeltas, using raw motion if it is configured and supported. Once released the cursor is free
+// to be used by tooling.
+func SetCursorCaptured(captured bool) {
+	if captured {
+		window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	} else {
+		window.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+	}
+	if glfw.RawMouseMotionSupported() {
+		raw := glfw.False
+		if captured && config.Get().Mouse.Raw {
+			raw = glfw.True
+		}
+		window.SetInputMode(glfw.RawMouseMotion, raw)
+	}
+	input.SetMouseCaptured(captured)
 }
 
-func handleEscape(m *messagebus.Message) {
-	pressedKeys := m.Data1.([]glfw.Key)
+func handleQuit(a input.ActionInput) {
+	if a.IsHeld("Quit") {
+		window.SetShouldClose(true)
+	}
+	a.Consume("Quit")
+}
 
-	for _, key := range pressedKeys {
-		if key == glfw.KeyEscape {
-			window.SetShouldClose(true)
+func handleToggleFullscreen(a input.ActionInput) {
+	if a.WasPressed("ToggleFullscreen") {
+		if mode == Fullscreen {
+			SetMode(Windowed)
+		} else {
+			SetMode(Fullscreen)
+		}
+	}
+	if a.WasPressed("ToggleBorderless") {
+		if mode == Borderless {
+			SetMode(Windowed)
+		} else {
+			SetMode(Borderless)
 		}
 	}
+	a.Consume("ToggleFullscreen", "ToggleBorderless")
+}
+
+func handleToggleCursorCapture(a input.ActionInput) {
+	if a.WasPressed("ToggleCursorCapture") {
+		SetCursorCaptured(!input.IsMouseCaptured())
+	}
+	a.Consume("ToggleCursorCapture")
 }
 
 // GetNumTilesX returns back the number of tiles in each the X dimension that are needed for the current window size.
 func GetNumTilesX() uint32 {
-	return (Width + TileSize - 1) / TileSize
+	tileSize := config.Get().Lighting.TileSize
+	return (size.Width + tileSize - 1) / tileSize
 }
 
 // GetNumTilesY returns back the number of tiles in each the Y dimension that are needed for the current window size.
 func GetNumTilesY() uint32 {
-	return (Height + TileSize - 1) / TileSize
+	tileSize := config.Get().Lighting.TileSize
+	return (size.Height + tileSize - 1) / tileSize
 }
 
 // GetTotalNumTiles returns back the total number of tiles required to cover the entire screen.